@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"auth-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxLookupUsers = 500
+
+type LookupUsersRequest struct {
+	IDs []uuid.UUID `json:"ids" binding:"required"`
+}
+
+type UserInfo struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Role  string    `json:"role"`
+}
+
+// LookupUsers возвращает найденных пользователей; отсутствующие ID просто не попадают в ответ
+func (h *AuthHandler) LookupUsers(c *gin.Context) {
+	var req LookupUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error:   "Invalid request data: " + err.Error(),
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	if len(req.IDs) > maxLookupUsers {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Success: false,
+			Error:   "Too many user IDs",
+			Code:    http.StatusBadRequest,
+		})
+		return
+	}
+
+	users := []UserInfo{}
+	if len(req.IDs) > 0 {
+		result := h.DB.Model(&models.User{}).
+			Select("id", "email", "role").
+			Where("id IN ?", req.IDs).
+			Scan(&users)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Success: false,
+				Error:   "Failed to fetch users",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    users,
+	})
+}
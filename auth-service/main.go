@@ -89,6 +89,13 @@ func main() {
 		auth.POST("/refresh", authHandler.RefreshToken)
 	}
 
+	// Internal routes (service-to-service, not exposed through the gateway)
+	internal := r.Group("/internal")
+	internal.Use(middleware.InternalAuth())
+	{
+		internal.POST("/users/lookup", authHandler.LookupUsers)
+	}
+
 	// Get port from environment
 	port := os.Getenv("PORT")
 	if port == "" {
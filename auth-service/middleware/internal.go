@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalAuth защищает служебные маршруты, которые вызывают другие сервисы.
+// Токен передаётся в заголовке X-Internal-Token и сверяется с INTERNAL_API_TOKEN.
+func InternalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expected := os.Getenv("INTERNAL_API_TOKEN")
+		if expected == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "Internal API is not configured",
+				"code":    http.StatusServiceUnavailable,
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader("X-Internal-Token")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Invalid internal token",
+				"code":    http.StatusUnauthorized,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -44,15 +44,15 @@ const TaskDetails: React.FC = () => {
 
     const handleSubmit = async (e: React.FormEvent) => {
         e.preventDefault();
-        if (id) {
-            await updateTask(id, formData);
+        if (id && currentTask) {
+            await updateTask(id, currentTask.version, formData);
             setIsEditing(false);
         }
     };
 
     const handleDelete = async () => {
-        if (id && window.confirm('Are you sure you want to delete this task?')) {
-            await deleteTask(id);
+        if (id && currentTask && window.confirm('Are you sure you want to delete this task?')) {
+            await deleteTask(id, currentTask.version);
             navigate('/dashboard');
         }
     };
@@ -15,12 +15,12 @@ const TaskItem: React.FC<TaskItemProps> = ({ task }) => {
 
     const handleDelete = () => {
         if (window.confirm('Are you sure you want to delete this task?')) {
-            deleteTask(task.id);
+            deleteTask(task.id, task.version);
         }
     };
 
     const handleStatusChange = (e: React.ChangeEvent<HTMLSelectElement>) => {
-        updateTaskStatus(task.id, e.target.value);
+        updateTaskStatus(task.id, task.version, e.target.value);
     };
 
     const handleViewDetails = () => {
@@ -5,7 +5,7 @@ import LoadingSpinner from '../common/LoadingSpinner';
 import ErrorMessage from '../common/ErrorMessage';
 
 const TaskList: React.FC = () => {
-    const { tasks, loading, error, fetchTasks, clearError } = useTasks();
+    const { tasks, loading, loadingMore, nextCursor, error, fetchTasks, fetchMoreTasks, clearError } = useTasks();
 
     useEffect(() => {
         fetchTasks();
@@ -27,6 +27,17 @@ const TaskList: React.FC = () => {
             ) : (
                 tasks.map(task => <TaskItem key={task.id} task={task} />)
             )}
+            {nextCursor && (
+                <div className="flex justify-center">
+                    <button
+                        onClick={fetchMoreTasks}
+                        disabled={loadingMore}
+                        className="px-4 py-2 text-blue-600 border border-blue-500 rounded-lg hover:bg-blue-50 disabled:opacity-50"
+                    >
+                        {loadingMore ? 'Loading...' : 'Load more'}
+                    </button>
+                </div>
+            )}
         </div>
     );
 };
@@ -0,0 +1,45 @@
+import React from 'react';
+import type { TaskStats } from '../../types/task';
+
+interface TaskStatsSummaryProps {
+    stats: TaskStats;
+}
+
+const formatDuration = (seconds: number | null): string => {
+    if (seconds === null) return '—';
+    const hours = seconds / 3600;
+    if (hours < 24) return `${hours.toFixed(1)} h`;
+    return `${(hours / 24).toFixed(1)} d`;
+};
+
+const TaskStatsSummary: React.FC<TaskStatsSummaryProps> = ({ stats }) => {
+    const cards = [
+        { label: 'Total', value: stats.total, color: 'text-gray-900' },
+        { label: 'In progress', value: stats.by_status.in_progress ?? 0, color: 'text-blue-600' },
+        { label: 'Overdue', value: stats.overdue, color: 'text-red-600' },
+        { label: 'Due this week', value: stats.due_this_week, color: 'text-yellow-600' },
+        {
+            label: `Completed (${stats.window.days} days)`,
+            value: `${stats.window.completion_rate.toFixed(0)}%`,
+            color: 'text-green-600',
+        },
+        {
+            label: 'Avg. time to complete',
+            value: formatDuration(stats.window.avg_completion_seconds),
+            color: 'text-gray-900',
+        },
+    ];
+
+    return (
+        <div className="grid grid-cols-2 md:grid-cols-3 lg:grid-cols-6 gap-4">
+            {cards.map(card => (
+                <div key={card.label} className="bg-white rounded-lg shadow px-4 py-3">
+                    <p className="text-sm text-gray-500">{card.label}</p>
+                    <p className={`text-2xl font-semibold ${card.color}`}>{card.value}</p>
+                </div>
+            ))}
+        </div>
+    );
+};
+
+export default TaskStatsSummary;
@@ -1,10 +1,12 @@
 import React, { createContext, useContext, useReducer, useCallback } from 'react';
-import type { TaskState, Task, CreateTaskRequest, UpdateTaskRequest } from '../types/task';
+import type { TaskState, Task, TaskListResponse, CreateTaskRequest, UpdateTaskRequest } from '../types/task';
 import { taskService } from '../services/taskService';
 
 type TaskAction =
     | { type: 'TASKS_LOADING' }
-    | { type: 'TASKS_SUCCESS'; payload: Task[] }
+    | { type: 'TASKS_SUCCESS'; payload: TaskPage }
+    | { type: 'TASKS_MORE_LOADING' }
+    | { type: 'TASKS_MORE_SUCCESS'; payload: TaskPage }
     | { type: 'TASK_SUCCESS'; payload: Task }
     | { type: 'TASKS_ERROR'; payload: string }
     | { type: 'TASK_CREATE'; payload: Task }
@@ -13,25 +15,59 @@ type TaskAction =
     | { type: 'CLEAR_ERROR' }
     | { type: 'CLEAR_CURRENT_TASK' };
 
+interface TaskPage {
+    tasks: Task[];
+    nextCursor: string | null;
+    totalCount: number;
+}
+
 const initialState: TaskState = {
     tasks: [],
     currentTask: null,
     loading: false,
+    loadingMore: false,
     error: null,
+    nextCursor: null,
+    totalCount: 0,
 };
 
+const toTaskPage = (response: TaskListResponse): TaskPage => ({
+    tasks: response.data ?? [],
+    nextCursor: response.meta?.next_cursor ?? null,
+    totalCount: response.meta?.total_count ?? response.data?.length ?? 0,
+});
+
 const taskReducer = (state: TaskState, action: TaskAction): TaskState => {
     switch (action.type) {
         case 'TASKS_LOADING':
             return { ...state, loading: true, error: null };
         case 'TASKS_SUCCESS':
-            return { ...state, loading: false, tasks: action.payload };
+            return {
+                ...state,
+                loading: false,
+                tasks: action.payload.tasks,
+                nextCursor: action.payload.nextCursor,
+                totalCount: action.payload.totalCount,
+            };
+        case 'TASKS_MORE_LOADING':
+            return { ...state, loadingMore: true, error: null };
+        case 'TASKS_MORE_SUCCESS': {
+            // Созданная в этой вкладке задача могла уже попасть в список — не дублируем её
+            const loaded = new Set(state.tasks.map(task => task.id));
+            return {
+                ...state,
+                loadingMore: false,
+                tasks: [...state.tasks, ...action.payload.tasks.filter(task => !loaded.has(task.id))],
+                nextCursor: action.payload.nextCursor,
+                totalCount: action.payload.totalCount,
+            };
+        }
         case 'TASK_SUCCESS':
             return { ...state, loading: false, currentTask: action.payload };
         case 'TASKS_ERROR':
-            return { ...state, loading: false, error: action.payload };
+            return { ...state, loading: false, loadingMore: false, error: action.payload };
         case 'TASK_CREATE':
-            return { ...state, tasks: [...state.tasks, action.payload] };
+            return { ...state, tasks: [...state.tasks, action.payload], totalCount: state.totalCount + 1 };
         case 'TASK_UPDATE':
             return {
                 ...state,
@@ -44,6 +80,7 @@ const taskReducer = (state: TaskState, action: TaskAction): TaskState => {
             return {
                 ...state,
                 tasks: state.tasks.filter(task => task.id !== action.payload),
+                totalCount: Math.max(state.totalCount - 1, 0),
                 currentTask: state.currentTask?.id === action.payload ? null : state.currentTask,
             };
         case 'CLEAR_ERROR':
@@ -57,11 +94,12 @@ const taskReducer = (state: TaskState, action: TaskAction): TaskState => {
 
 interface TaskContextType extends TaskState {
     fetchTasks: () => Promise<void>;
+    fetchMoreTasks: () => Promise<void>;
     fetchTask: (id: string) => Promise<void>;
     createTask: (task: CreateTaskRequest) => Promise<void>;
-    updateTask: (id: string, task: UpdateTaskRequest) => Promise<void>;
-    deleteTask: (id: string) => Promise<void>;
-    updateTaskStatus: (id: string, status: string) => Promise<void>;
+    updateTask: (id: string, version: number, task: UpdateTaskRequest) => Promise<void>;
+    deleteTask: (id: string, version: number) => Promise<void>;
+    updateTaskStatus: (id: string, version: number, status: string) => Promise<void>;
     clearError: () => void;
     clearCurrentTask: () => void;
 }
@@ -71,12 +109,19 @@ const TaskContext = createContext<TaskContextType | undefined>(undefined);
 export const TaskProvider: React.FC<{ children: React.ReactNode }> = ({ children }) => {
     const [state, dispatch] = useReducer(taskReducer, initialState);
 
+    // 412: задачу изменил кто-то другой — подставляем актуальную версию из ответа
+    const handleConflict = useCallback((error: any) => {
+        if (error.response?.status === 412 && error.response.data?.details) {
+            dispatch({ type: 'TASK_UPDATE', payload: error.response.data.details });
+        }
+    }, []);
+
     const fetchTasks = useCallback(async () => {
         dispatch({ type: 'TASKS_LOADING' });
         try {
             const response = await taskService.getTasks();
             if (response.success && response.data) {
-                dispatch({ type: 'TASKS_SUCCESS', payload: response.data });
+                dispatch({ type: 'TASKS_SUCCESS', payload: toTaskPage(response) });
             } else {
                 dispatch({ type: 'TASKS_ERROR', payload: response.error || 'Failed to fetch tasks' });
             }
@@ -88,6 +133,27 @@ export const TaskProvider: React.FC<{ children: React.ReactNode }> = ({ children
         }
     }, []);
 
+    // Следующая страница списка по next_cursor; без курсора загружать нечего
+    const fetchMoreTasks = useCallback(async () => {
+        if (!state.nextCursor || state.loadingMore) {
+            return;
+        }
+        dispatch({ type: 'TASKS_MORE_LOADING' });
+        try {
+            const response = await taskService.getTasks(state.nextCursor);
+            if (response.success && response.data) {
+                dispatch({ type: 'TASKS_MORE_SUCCESS', payload: toTaskPage(response) });
+            } else {
+                dispatch({ type: 'TASKS_ERROR', payload: response.error || 'Failed to fetch tasks' });
+            }
+        } catch (error: any) {
+            dispatch({
+                type: 'TASKS_ERROR',
+                payload: error.response?.data?.error || 'Failed to fetch tasks',
+            });
+        }
+    }, [state.nextCursor, state.loadingMore]);
+
     const fetchTask = useCallback(async (id: string) => {
         dispatch({ type: 'TASKS_LOADING' });
         try {
@@ -122,55 +188,58 @@ export const TaskProvider: React.FC<{ children: React.ReactNode }> = ({ children
         }
     }, []);
 
-    const updateTask = useCallback(async (id: string, taskData: UpdateTaskRequest) => {
+    const updateTask = useCallback(async (id: string, version: number, taskData: UpdateTaskRequest) => {
         dispatch({ type: 'TASKS_LOADING' });
         try {
-            const response = await taskService.updateTask(id, taskData);
+            const response = await taskService.updateTask(id, version, taskData);
             if (response.success && response.data) {
                 dispatch({ type: 'TASK_UPDATE', payload: response.data });
             } else {
                 dispatch({ type: 'TASKS_ERROR', payload: response.error || 'Failed to update task' });
             }
         } catch (error: any) {
+            handleConflict(error);
             dispatch({
                 type: 'TASKS_ERROR',
                 payload: error.response?.data?.error || 'Failed to update task',
             });
         }
-    }, []);
+    }, [handleConflict]);
 
-    const deleteTask = useCallback(async (id: string) => {
+    const deleteTask = useCallback(async (id: string, version: number) => {
         dispatch({ type: 'TASKS_LOADING' });
         try {
-            const response = await taskService.deleteTask(id);
+            const response = await taskService.deleteTask(id, version);
             if (response.success) {
                 dispatch({ type: 'TASK_DELETE', payload: id });
             } else {
                 dispatch({ type: 'TASKS_ERROR', payload: response.error || 'Failed to delete task' });
             }
         } catch (error: any) {
+            handleConflict(error);
             dispatch({
                 type: 'TASKS_ERROR',
                 payload: error.response?.data?.error || 'Failed to delete task',
             });
         }
-    }, []);
+    }, [handleConflict]);
 
-    const updateTaskStatus = useCallback(async (id: string, status: string) => {
+    const updateTaskStatus = useCallback(async (id: string, version: number, status: string) => {
         try {
-            const response = await taskService.updateTaskStatus(id, status);
+            const response = await taskService.updateTaskStatus(id, version, status);
             if (response.success && response.data) {
                 dispatch({ type: 'TASK_UPDATE', payload: response.data });
             } else {
                 dispatch({ type: 'TASKS_ERROR', payload: response.error || 'Failed to update task status' });
             }
         } catch (error: any) {
+            handleConflict(error);
             dispatch({
                 type: 'TASKS_ERROR',
                 payload: error.response?.data?.error || 'Failed to update task status',
             });
         }
-    }, []);
+    }, [handleConflict]);
 
     const clearError = useCallback(() => {
         dispatch({ type: 'CLEAR_ERROR' });
@@ -185,6 +254,7 @@ export const TaskProvider: React.FC<{ children: React.ReactNode }> = ({ children
             value={{
                 ...state,
                 fetchTasks,
+                fetchMoreTasks,
                 fetchTask,
                 createTask,
                 updateTask,
@@ -4,21 +4,39 @@ import { useAuth } from '../contexts/AuthContext';
 import Header from '../components/common/Header';
 import TaskList from '../components/tasks/TaskList';
 import TaskForm from '../components/tasks/TaskForm';
+import TaskStatsSummary from '../components/tasks/TaskStatsSummary';
 import LoadingSpinner from '../components/common/LoadingSpinner';
 import ErrorMessage from '../components/common/ErrorMessage';
+import { taskService } from '../services/taskService';
+import type { TaskStats } from '../types/task';
 
 const Dashboard: React.FC = () => {
     const { user } = useAuth();
-    const { tasks, loading, error, fetchTasks, clearError } = useTasks();
+    const { totalCount, loading, error, fetchTasks, clearError } = useTasks();
     const [showTaskForm, setShowTaskForm] = useState(false);
+    const [stats, setStats] = useState<TaskStats | null>(null);
+
+    // Сводка считается на сервере, а не по загруженному списку задач
+    const fetchStats = async () => {
+        try {
+            const response = await taskService.getStats();
+            if (response.success && response.data) {
+                setStats(response.data);
+            }
+        } catch {
+            setStats(null);
+        }
+    };
 
     useEffect(() => {
         fetchTasks();
+        fetchStats();
     }, []); // Убрана зависимость fetchTasks
 
     const handleTaskCreated = () => {
         setShowTaskForm(false);
         fetchTasks(); // Явный вызов после создания задачи
+        fetchStats();
     };
 
     return (
@@ -63,6 +81,12 @@ const Dashboard: React.FC = () => {
                     )}
                 </div>
 
+                {stats && (
+                    <div className="mb-8">
+                        <TaskStatsSummary stats={stats} />
+                    </div>
+                )}
+
                 {error && (
                     <div className="mb-6">  
                         <ErrorMessage message={error} onClose={clearError} />
@@ -78,7 +102,7 @@ const Dashboard: React.FC = () => {
                 <div className="bg-white rounded-lg shadow">
                     <div className="px-6 py-4 border-b border-gray-200">
                         <h2 className="text-xl font-semibold text-gray-800">
-                            Tasks ({tasks.length})
+                            Tasks ({totalCount})
                         </h2>
                     </div>
                     <div className="p-6">
@@ -1,10 +1,31 @@
 import { api } from './api';
-import type {Task, CreateTaskRequest, UpdateTaskRequest} from '../types/task.ts';
+import type {Task, CreateTaskRequest, UpdateTaskRequest, TaskSearchResult, TaskStats, TaskListResponse} from '../types/task.ts';
 import type {ApiResponse} from '../types/common';
 
+// Изменяющие запросы передают версию задачи; при расхождении сервер отвечает 412
+const ifMatch = (version: number) => ({ headers: { 'If-Match': `"${version}"` } });
+
+// Размер страницы списка задач; следующие страницы загружаются по запросу
+const TASK_PAGE_SIZE = 50;
+
 export const taskService = {
-    async getTasks(): Promise<ApiResponse<Task[]>> {
-        const response = await api.get('/tasks');
+    // Одна страница списка; cursor — meta.next_cursor предыдущей страницы
+    async getTasks(cursor?: string): Promise<TaskListResponse> {
+        const params: Record<string, string | number> = { limit: TASK_PAGE_SIZE };
+        if (cursor) {
+            params.cursor = cursor;
+        }
+        const response = await api.get('/tasks', { params });
+        return response.data;
+    },
+
+    async searchTasks(q: string): Promise<ApiResponse<TaskSearchResult[]>> {
+        const response = await api.get('/tasks/search', { params: { q } });
+        return response.data;
+    },
+
+    async getStats(days = 30): Promise<ApiResponse<TaskStats>> {
+        const response = await api.get('/tasks/stats', { params: { days } });
         return response.data;
     },
 
@@ -18,18 +39,18 @@ export const taskService = {
         return response.data;
     },
 
-    async updateTask(id: string, task: UpdateTaskRequest): Promise<ApiResponse<Task>> {
-        const response = await api.put(`/tasks/${id}`, task);
+    async updateTask(id: string, version: number, task: UpdateTaskRequest): Promise<ApiResponse<Task>> {
+        const response = await api.put(`/tasks/${id}`, task, ifMatch(version));
         return response.data;
     },
 
-    async deleteTask(id: string): Promise<ApiResponse<void>> {
-        const response = await api.delete(`/tasks/${id}`);
+    async deleteTask(id: string, version: number): Promise<ApiResponse<void>> {
+        const response = await api.delete(`/tasks/${id}`, ifMatch(version));
         return response.data;
     },
 
-    async updateTaskStatus(id: string, status: string): Promise<ApiResponse<Task>> {
-        const response = await api.patch(`/tasks/${id}/status`, { status });
+    async updateTaskStatus(id: string, version: number, status: string): Promise<ApiResponse<Task>> {
+        const response = await api.patch(`/tasks/${id}/status`, { status }, ifMatch(version));
         return response.data;
     }
 };
\ No newline at end of file
@@ -1,3 +1,5 @@
+import type {ApiResponse} from './common';
+
 export interface Task {
     id: string;
     title: string;
@@ -8,6 +10,28 @@ export interface Task {
     created_by: string;
     created_at: string;
     updated_at: string;
+    version: number;
+    series_id?: string;
+    occurrence?: number;
+    external_id?: string;
+}
+
+export interface TaskSearchResult extends Task {
+    rank: number;
+    title_highlight: string;
+    snippet: string;
+}
+
+// Метаданные страницы GET /tasks; next_cursor равен null на последней странице
+export interface TaskListMeta {
+    next_cursor: string | null;
+    total_count: number;
+    limit: number;
+    view_id?: string;
+}
+
+export interface TaskListResponse extends ApiResponse<Task[]> {
+    meta?: TaskListMeta;
 }
 
 export interface CreateTaskRequest {
@@ -16,6 +40,7 @@ export interface CreateTaskRequest {
     status?: string;
     priority?: string;
     due_date?: string;
+    recurrence?: string;
 }
 
 export interface UpdateTaskRequest {
@@ -26,9 +51,30 @@ export interface UpdateTaskRequest {
     due_date?: string;
 }
 
+// nextCursor — курсор следующей страницы списка, null если загружены все задачи
 export interface TaskState {
     tasks: Task[];
     currentTask: Task | null;
     loading: boolean;
+    loadingMore: boolean;
     error: string | null;
-}
\ No newline at end of file
+    nextCursor: string | null;
+    totalCount: number;
+}
+export interface TaskStats {
+    scope: 'mine' | 'all';
+    total: number;
+    by_status: Record<string, number>;
+    by_priority: Record<string, number>;
+    overdue: number;
+    due_this_week: number;
+    window: {
+        days: number;
+        since: string;
+        created: number;
+        completed: number;
+        completion_rate: number;
+        completed_in_window: number;
+        avg_completion_seconds: number | null;
+    };
+}
@@ -0,0 +1,54 @@
+// Package broker публикует доменные события задач для других сервисов.
+// События берутся из outbox фоновым relay, поэтому доставка — не менее одного раза:
+// получатели отбрасывают повторы по ID события.
+package broker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"task-service/config"
+
+	"github.com/google/uuid"
+)
+
+// Message — одно доменное событие. Key — ключ упорядочивания (ID задачи),
+// Payload — тело события в JSON.
+type Message struct {
+	ID         uuid.UUID
+	Type       string
+	Key        string
+	OccurredAt time.Time
+	Payload    []byte
+}
+
+type Publisher interface {
+	// Publish возвращает nil только после того, как брокер принял сообщение
+	Publish(ctx context.Context, m Message) error
+	Close() error
+}
+
+// LogPublisher пишет события в лог сервиса; для разработки без брокера
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, m Message) error {
+	log.Printf("Domain event: %s %s (task %s)", m.Type, m.ID, m.Key)
+	return nil
+}
+
+func (LogPublisher) Close() error {
+	return nil
+}
+
+// New создаёт издателя по TASK_OUTBOX_BROKER
+func New(cfg *config.Config) (Publisher, error) {
+	switch cfg.OutboxBroker {
+	case "redis":
+		return NewRedisStreams(cfg.RedisURL, cfg.OutboxStream, cfg.OutboxStreamMaxLen)
+	case "log":
+		return LogPublisher{}, nil
+	}
+	return nil, fmt.Errorf("unknown outbox broker %q (allowed: redis, log)", cfg.OutboxBroker)
+}
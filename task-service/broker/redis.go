@@ -0,0 +1,54 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreams добавляет события в Redis Stream командой XADD. Порядок записей в потоке
+// совпадает с порядком публикации; maxLen > 0 — поток приблизительно обрезается до maxLen записей.
+type RedisStreams struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreams(redisURL, stream string, maxLen int64) (*RedisStreams, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse REDIS_URL: %w", err)
+	}
+	return &RedisStreams{
+		client: redis.NewClient(opts),
+		stream: stream,
+		maxLen: maxLen,
+	}, nil
+}
+
+func (r *RedisStreams) Publish(ctx context.Context, m Message) error {
+	args := &redis.XAddArgs{
+		Stream: r.stream,
+		Values: map[string]interface{}{
+			"event_id":    m.ID.String(),
+			"type":        m.Type,
+			"task_id":     m.Key,
+			"occurred_at": m.OccurredAt.UTC().Format(time.RFC3339Nano),
+			"payload":     string(m.Payload),
+		},
+	}
+	if r.maxLen > 0 {
+		args.MaxLen = r.maxLen
+		args.Approx = true
+	}
+	if err := r.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("xadd %s: %w", r.stream, err)
+	}
+	return nil
+}
+
+func (r *RedisStreams) Close() error {
+	return r.client.Close()
+}
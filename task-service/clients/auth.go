@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserInfo struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Role  string    `json:"role"`
+}
+
+// AuthClient обращается к внутреннему API auth-service
+type AuthClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func NewAuthClient() *AuthClient {
+	baseURL := os.Getenv("AUTH_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://auth-service:8081"
+	}
+
+	return &AuthClient{
+		BaseURL:    baseURL,
+		Token:      os.Getenv("INTERNAL_API_TOKEN"),
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// LookupUsers возвращает найденных пользователей по ID. Отсутствующие ID в результат не попадают.
+func (a *AuthClient) LookupUsers(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]UserInfo, error) {
+	users := make(map[uuid.UUID]UserInfo, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.BaseURL+"/internal/users/lookup", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Internal-Token", a.Token)
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth-service returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Success bool       `json:"success"`
+		Data    []UserInfo `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid auth-service response: %w", err)
+	}
+
+	for _, u := range payload.Data {
+		users[u.ID] = u
+	}
+	return users, nil
+}
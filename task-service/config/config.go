@@ -0,0 +1,200 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config содержит настройки task-service, которые задаются через переменные окружения
+type Config struct {
+	// Максимальная глубина вложенности подзадач (корневая задача — уровень 1)
+	MaxTaskDepth int
+	// Сколько дней задача хранится в корзине до окончательного удаления (0 — не удалять)
+	TrashRetentionDays int
+	// Как часто запускается очистка корзины, в минутах
+	TrashPurgeIntervalMinutes int
+	// Максимальное число операций в одном запросе POST /tasks/bulk
+	MaxBulkOperations int
+	// Максимальное число строк в одном импорте
+	MaxImportRows int
+	// Через сколько часов остановка таймера требует подтверждения (0 — не требует)
+	TimerConfirmHours int
+
+	// За сколько до срока отправлять напоминания; 0 — напоминание о просрочке
+	ReminderOffsets []time.Duration
+	// Как часто проверяются сроки задач, в секундах (0 — напоминания выключены)
+	ReminderIntervalSeconds int
+	// Каналы доставки напоминаний: log, webhook, smtp
+	ReminderNotifiers []string
+	// Адрес, на который webhook-канал отправляет напоминания
+	ReminderWebhookURL string
+	// Как часто отправляются уведомления наблюдателям, в секундах (0 — не отправляются).
+	// Каналы доставки те же, что у напоминаний.
+	WatcherNotifyIntervalSeconds int
+
+	// Как часто отправляются исходящие вебхуки, в секундах (0 — не отправляются)
+	WebhookIntervalSeconds int
+	// Число попыток доставки, после которого она переходит в состояние dead
+	WebhookMaxAttempts int
+	// Таймаут одной доставки, в секундах
+	WebhookTimeoutSeconds int
+	// Сколько дней хранятся успешные доставки в журнале (0 — не удаляются)
+	WebhookLogRetentionDays int
+	// Разрешить вебхуки на внутренние адреса (loopback, частные сети)
+	WebhookAllowPrivateURLs bool
+
+	// Куда relay публикует доменные события из outbox: redis или log
+	OutboxBroker string
+	// Как часто relay проверяет outbox, в секундах (0 — события не публикуются)
+	OutboxIntervalSeconds int
+	// Сколько часов хранятся опубликованные события (0 — не удаляются)
+	OutboxRetentionHours int
+	// Redis Stream для доменных событий и его приблизительная длина (0 — не обрезается)
+	OutboxStream       string
+	OutboxStreamMaxLen int64
+	RedisURL           string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// Где хранятся вложения: local или s3
+	AttachmentStorage string
+	// Папка для вложений при локальном хранилище
+	AttachmentDir string
+	// Максимальный размер одного вложения и суммарный размер вложений пользователя, в байтах
+	MaxAttachmentSize   int64
+	UserAttachmentQuota int64
+	// Разрешённые типы содержимого вложений
+	AttachmentAllowedTypes []string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+}
+
+func Load() *Config {
+	return &Config{
+		MaxTaskDepth:              getEnvInt("TASK_MAX_DEPTH", 5),
+		TrashRetentionDays:        getEnvInt("TASK_TRASH_RETENTION_DAYS", 30),
+		TrashPurgeIntervalMinutes: getEnvInt("TASK_TRASH_PURGE_INTERVAL_MINUTES", 60),
+		MaxBulkOperations:         getEnvInt("TASK_BULK_MAX_OPERATIONS", 100),
+		MaxImportRows:             getEnvInt("TASK_IMPORT_MAX_ROWS", 5000),
+		TimerConfirmHours:         getEnvInt("TASK_TIMER_CONFIRM_HOURS", 10),
+
+		ReminderOffsets:         getEnvOffsets("TASK_REMINDER_OFFSETS", "24h,1h,overdue"),
+		ReminderIntervalSeconds: getEnvInt("TASK_REMINDER_INTERVAL_SECONDS", 60),
+		ReminderNotifiers:       getEnvList("TASK_REMINDER_NOTIFIERS", "log"),
+		ReminderWebhookURL:      os.Getenv("TASK_REMINDER_WEBHOOK_URL"),
+
+		WatcherNotifyIntervalSeconds: getEnvInt("TASK_WATCHER_NOTIFY_INTERVAL_SECONDS", 30),
+
+		WebhookIntervalSeconds:  getEnvInt("TASK_WEBHOOK_INTERVAL_SECONDS", 10),
+		WebhookMaxAttempts:      getEnvInt("TASK_WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookTimeoutSeconds:   getEnvInt("TASK_WEBHOOK_TIMEOUT_SECONDS", 10),
+		WebhookLogRetentionDays: getEnvInt("TASK_WEBHOOK_LOG_RETENTION_DAYS", 14),
+		WebhookAllowPrivateURLs: getEnvBool("TASK_WEBHOOK_ALLOW_PRIVATE_URLS", false),
+
+		OutboxBroker:          getEnv("TASK_OUTBOX_BROKER", "redis"),
+		OutboxIntervalSeconds: getEnvInt("TASK_OUTBOX_INTERVAL_SECONDS", 1),
+		OutboxRetentionHours:  getEnvInt("TASK_OUTBOX_RETENTION_HOURS", 72),
+		OutboxStream:          getEnv("TASK_OUTBOX_STREAM", "task-events"),
+		OutboxStreamMaxLen:    int64(getEnvInt("TASK_OUTBOX_STREAM_MAXLEN", 100000)),
+		RedisURL:              getEnv("REDIS_URL", "redis://redis:6379"),
+
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     getEnvInt("SMTP_PORT", 587),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     os.Getenv("SMTP_FROM"),
+
+		AttachmentStorage:   getEnv("TASK_ATTACHMENT_STORAGE", "local"),
+		AttachmentDir:       getEnv("TASK_ATTACHMENT_DIR", "./data/attachments"),
+		MaxAttachmentSize:   int64(getEnvInt("TASK_ATTACHMENT_MAX_FILE_MB", 25)) << 20,
+		UserAttachmentQuota: int64(getEnvInt("TASK_ATTACHMENT_USER_QUOTA_MB", 500)) << 20,
+		AttachmentAllowedTypes: getEnvList("TASK_ATTACHMENT_ALLOWED_TYPES",
+			"image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain,application/zip"),
+
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Region:          getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Printf("Invalid value for %s: %q, using default %d", key, v, fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("Invalid value for %s: %q, using default %t", key, v, fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvList разбирает список через запятую; пустые элементы пропускаются
+func getEnvList(key, fallback string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		v = fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvOffsets разбирает список длительностей ("24h,30m"); "overdue" означает 0
+func getEnvOffsets(key, fallback string) []time.Duration {
+	var offsets []time.Duration
+	for _, item := range getEnvList(key, fallback) {
+		if item == "overdue" {
+			offsets = append(offsets, 0)
+			continue
+		}
+		d, err := time.ParseDuration(item)
+		if err != nil || d <= 0 {
+			log.Printf("Invalid offset in %s: %q, skipping", key, item)
+			continue
+		}
+		offsets = append(offsets, d)
+	}
+	return offsets
+}
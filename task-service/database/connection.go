@@ -22,7 +22,8 @@ func ConnectDB() *gorm.DB {
 	// Попытки подключения с задержкой
 	maxRetries := 10
 	for i := 0; i < maxRetries; i++ {
-		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		// TranslateError позволяет отличать нарушение уникальности (gorm.ErrDuplicatedKey)
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 		if err != nil {
 			if i == maxRetries-1 {
 				log.Fatal("Failed to connect to database after multiple attempts: ", err)
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxAssigneesPerRequest = 50
+
+var errNotAssigned = errors.New("user is not assigned to this task")
+
+type AssignTaskRequest struct {
+	UserIDs []uuid.UUID `json:"user_ids" binding:"required,min=1"`
+}
+
+func (h *TaskHandler) AssignTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req AssignTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+	if len(req.UserIDs) > maxAssigneesPerRequest {
+		respondError(c, http.StatusBadRequest, "Too many assignees in one request")
+		return
+	}
+
+	// Назначать исполнителей могут автор задачи, owner/editor проекта и администратор
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	users, err := h.Auth.LookupUsers(c.Request.Context(), req.UserIDs)
+	if err != nil {
+		respondError(c, http.StatusBadGateway, "Failed to verify users")
+		return
+	}
+
+	var unknown []string
+	assignees := make([]models.TaskAssignee, 0, len(req.UserIDs))
+	for _, id := range req.UserIDs {
+		if _, exists := users[id]; !exists {
+			unknown = append(unknown, id.String())
+			continue
+		}
+		assignees = append(assignees, models.TaskAssignee{
+			TaskID:     task.ID,
+			UserID:     id,
+			AssignedBy: userUUID,
+		})
+	}
+	if len(unknown) > 0 {
+		respondError(c, http.StatusBadRequest, "Unknown user IDs: "+strings.Join(unknown, ", "))
+		return
+	}
+
+	// Повторное назначение того же пользователя не считается ошибкой и события не порождает
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, task.ID); err != nil {
+			return err
+		}
+
+		var existing []uuid.UUID
+		if err := tx.Model(&models.TaskAssignee{}).Where("task_id = ?", task.ID).Pluck("user_id", &existing).Error; err != nil {
+			return err
+		}
+		assigned := map[uuid.UUID]bool{}
+		for _, id := range existing {
+			assigned[id] = true
+		}
+		var added []uuid.UUID
+		for _, a := range assignees {
+			if !assigned[a.UserID] {
+				assigned[a.UserID] = true
+				added = append(added, a.UserID)
+			}
+		}
+		after := append(append([]uuid.UUID{}, existing...), added...)
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignees).Error; err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			return nil
+		}
+		return recordTaskEventForUsers(tx, task.ID, userUUID, models.TaskEventAssigned, assigneeChanges(existing, after), added)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, "Task not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to assign task")
+		return
+	}
+
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    task,
+	})
+}
+
+func (h *TaskHandler) UnassignTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	assigneeUUID, ok := uuidParam(c, "userId", "user")
+	if !ok {
+		return
+	}
+
+	// Тот, кто может назначать, снимает любого исполнителя; исполнитель может снять себя сам
+	level := accessEdit
+	if assigneeUUID == userUUID {
+		level = accessView
+	}
+	task, ok := h.findTask(c, taskUUID, userUUID, role, level)
+	if !ok {
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, task.ID); err != nil {
+			return err
+		}
+
+		var existing []uuid.UUID
+		if err := tx.Model(&models.TaskAssignee{}).Where("task_id = ?", task.ID).Pluck("user_id", &existing).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("task_id = ? AND user_id = ?", task.ID, assigneeUUID).Delete(&models.TaskAssignee{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNotAssigned
+		}
+
+		after := make([]uuid.UUID, 0, len(existing))
+		for _, id := range existing {
+			if id != assigneeUUID {
+				after = append(after, id)
+			}
+		}
+		return recordTaskEventForUsers(tx, task.ID, userUUID, models.TaskEventUnassigned, assigneeChanges(existing, after), []uuid.UUID{assigneeUUID})
+	})
+	if errors.Is(err, errNotAssigned) {
+		respondError(c, http.StatusNotFound, "User is not assigned to this task")
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, "Task not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to unassign task")
+		return
+	}
+
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    task,
+	})
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"task-service/models"
+	"task-service/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// Запас на заголовки multipart сверх максимального размера файла
+	attachmentFormOverhead  = 1 << 20
+	maxAttachmentNameLength = 255
+	// Сколько первых байт файла смотрит http.DetectContentType
+	contentSniffLength = 512
+)
+
+var errAttachmentQuota = errors.New("attachment quota exceeded")
+
+// attachmentFileName оставляет от имени файла только базовое имя разумной длины
+func attachmentFileName(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "file"
+	}
+	for len(name) > maxAttachmentNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// userAttachmentUsage возвращает суммарный размер вложений пользователя в байтах
+func userAttachmentUsage(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var used int64
+	err := db.Model(&models.TaskAttachment{}).
+		Select("COALESCE(SUM(size_bytes), 0)").
+		Where("uploaded_by = ?", userID).
+		Scan(&used).Error
+	return used, err
+}
+
+func (h *TaskHandler) respondQuotaExceeded(c *gin.Context, used int64) {
+	respondErrorWithDetails(c, http.StatusRequestEntityTooLarge, "Attachment quota exceeded", gin.H{
+		"used_bytes":  used,
+		"quota_bytes": h.Config.UserAttachmentQuota,
+	})
+}
+
+func (h *TaskHandler) findAttachment(c *gin.Context, taskID uuid.UUID) (*models.TaskAttachment, bool) {
+	attachmentUUID, ok := uuidParam(c, "attachmentId", "attachment")
+	if !ok {
+		return nil, false
+	}
+
+	var attachment models.TaskAttachment
+	if err := h.DB.Where("id = ? AND task_id = ?", attachmentUUID, taskID).First(&attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Attachment not found")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch attachment")
+		return nil, false
+	}
+	return &attachment, true
+}
+
+func (h *TaskHandler) GetAttachments(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	var attachments []models.TaskAttachment
+	if err := h.DB.Where("task_id = ?", task.ID).Order("created_at").Find(&attachments).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    attachments,
+	})
+}
+
+// UploadAttachment принимает файл в поле file формы multipart/form-data.
+// Тип содержимого определяется по самому файлу, а не по заголовку клиента.
+func (h *TaskHandler) UploadAttachment(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	// Прикреплять файлы могут те, кто может менять статус задачи
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessStatus)
+	if !ok {
+		return
+	}
+
+	maxSize := h.Config.MaxAttachmentSize
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+attachmentFormOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("File exceeds the maximum size of %d bytes", maxSize))
+			return
+		}
+		respondError(c, http.StatusBadRequest, "A file must be sent in the 'file' field of a multipart form")
+		return
+	}
+	if header.Size > maxSize {
+		respondError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("File exceeds the maximum size of %d bytes", maxSize))
+		return
+	}
+	if header.Size == 0 {
+		respondError(c, http.StatusBadRequest, "File is empty")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+	defer file.Close()
+
+	sniff := make([]byte, contentSniffLength)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		respondError(c, http.StatusBadRequest, "Failed to read uploaded file")
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(sniff[:n]))
+	if !slices.Contains(h.Config.AttachmentAllowedTypes, contentType) {
+		respondErrorWithDetails(c, http.StatusUnsupportedMediaType, "File type is not allowed", gin.H{
+			"content_type": contentType,
+			"allowed":      h.Config.AttachmentAllowedTypes,
+		})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to read uploaded file")
+		return
+	}
+
+	// Предварительная проверка квоты, чтобы не загружать в хранилище заведомо лишний файл;
+	// окончательная — в транзакции ниже
+	used, err := userAttachmentUsage(h.DB, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check attachment quota")
+		return
+	}
+	if used+header.Size > h.Config.UserAttachmentQuota {
+		h.respondQuotaExceeded(c, used)
+		return
+	}
+
+	attachment := models.TaskAttachment{
+		ID:          uuid.New(),
+		TaskID:      &task.ID,
+		UploadedBy:  userUUID,
+		FileName:    attachmentFileName(header.Filename),
+		ContentType: contentType,
+		SizeBytes:   header.Size,
+	}
+	attachment.StorageKey = "tasks/" + task.ID.String() + "/" + attachment.ID.String()
+
+	hash := sha256.New()
+	if err := h.Storage.Put(c.Request.Context(), attachment.StorageKey, io.TeeReader(file, hash), header.Size, contentType); err != nil {
+		log.Printf("Attachment upload for task %s failed: %v", task.ID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to store attachment")
+		return
+	}
+	attachment.ChecksumSHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Параллельные загрузки одного пользователя проверяют квоту по очереди
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "attachments:"+userUUID.String()).Error; err != nil {
+			return err
+		}
+		var err error
+		if used, err = userAttachmentUsage(tx, userUUID); err != nil {
+			return err
+		}
+		if used+attachment.SizeBytes > h.Config.UserAttachmentQuota {
+			return errAttachmentQuota
+		}
+		return tx.Create(&attachment).Error
+	})
+	if err != nil {
+		if delErr := h.Storage.Delete(c.Request.Context(), attachment.StorageKey); delErr != nil {
+			log.Printf("Failed to remove stored attachment %s: %v", attachment.StorageKey, delErr)
+		}
+		if errors.Is(err, errAttachmentQuota) {
+			h.respondQuotaExceeded(c, used)
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to save attachment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    attachment,
+	})
+}
+
+// DownloadAttachment отдаёт содержимое файла. ETag — контрольная сумма SHA-256.
+func (h *TaskHandler) DownloadAttachment(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.findAttachment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	etag := `"` + attachment.ChecksumSHA256 + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.Storage.Get(c.Request.Context(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		respondError(c, http.StatusNotFound, "Attachment content not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Attachment download %s failed: %v", attachment.ID, err)
+		respondError(c, http.StatusInternalServerError, "Failed to read attachment")
+		return
+	}
+	defer body.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", etag)
+	c.Header("X-Checksum-SHA256", attachment.ChecksumSHA256)
+	c.Status(http.StatusOK)
+
+	if _, err := io.Copy(c.Writer, body); err != nil {
+		log.Printf("Attachment download %s interrupted: %v", attachment.ID, err)
+	}
+}
+
+// DeleteAttachment удаляет вложение. Удалить может загрузивший его, редактор задачи или администратор.
+func (h *TaskHandler) DeleteAttachment(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.findAttachment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	if attachment.UploadedBy != userUUID {
+		var editable int64
+		if err := h.DB.Model(&models.Task{}).Scopes(editableTasks(userUUID, role)).Where("tasks.id = ?", task.ID).Count(&editable).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to check task access")
+			return
+		}
+		if editable == 0 {
+			respondError(c, http.StatusForbidden, "Only the uploader or a task editor can delete an attachment")
+			return
+		}
+	}
+
+	if err := h.DB.Delete(attachment).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete attachment")
+		return
+	}
+	// Запись уже удалена; если файл удалить не удалось, он просто останется в хранилище
+	if err := h.Storage.Delete(c.Request.Context(), attachment.StorageKey); err != nil {
+		log.Printf("Failed to remove stored attachment %s: %v", attachment.StorageKey, err)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Attachment deleted successfully"},
+	})
+}
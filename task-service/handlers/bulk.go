@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-service/models"
+	"task-service/recurrence"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	bulkOpCreate = "create"
+	bulkOpUpdate = "update"
+	bulkOpStatus = "status"
+	bulkOpDelete = "delete"
+
+	bulkModeAtomic     = "atomic"
+	bulkModeBestEffort = "best_effort"
+)
+
+type BulkRequest struct {
+	// Mode: atomic (по умолчанию) — всё в одной транзакции; best_effort — каждая операция отдельно
+	Mode       string          `json:"mode"`
+	Operations []BulkOperation `json:"operations" binding:"required"`
+}
+
+// BulkOperation — одна операция пакета. Для update поля передаются в Fields по правилам
+// JSON Merge Patch, как в PATCH /tasks/:id. Version обязателен для update, status и delete:
+// операция выполняется, только если версия задачи совпадает (аналог If-Match).
+type BulkOperation struct {
+	Op      string                     `json:"op"`
+	ID      *uuid.UUID                 `json:"id"`
+	Version *int                       `json:"version"`
+	Task    *CreateTaskRequest         `json:"task"`
+	Fields  map[string]json.RawMessage `json:"fields"`
+	Status  string                     `json:"status"`
+	Force   bool                       `json:"force"`
+}
+
+type BulkOperationResult struct {
+	Index   int          `json:"index"`
+	Op      string       `json:"op"`
+	Success bool         `json:"success"`
+	Code    int          `json:"code"`
+	Task    *models.Task `json:"task,omitempty"`
+	// NextOccurrence — следующее повторение, созданное при завершении повторяющейся задачи
+	NextOccurrence *models.Task `json:"next_occurrence,omitempty"`
+	Error          string       `json:"error,omitempty"`
+	Details        interface{}  `json:"details,omitempty"`
+}
+
+// bulkError — ошибка отдельной операции с HTTP-кодом, который вернул бы одиночный эндпоинт
+type bulkError struct {
+	Code    int
+	Message string
+	Details interface{}
+}
+
+func (e *bulkError) Error() string {
+	return e.Message
+}
+
+func newBulkError(code int, message string) *bulkError {
+	return &bulkError{Code: code, Message: message}
+}
+
+// bulkFindTask загружает задачу с нужным уровнем доступа внутри транзакции пакета
+// и проверяет версию. Без версии операция отклоняется с 428, как запрос без If-Match.
+func bulkFindTask(tx *gorm.DB, op *BulkOperation, userID uuid.UUID, role string, level taskAccess) (*models.Task, error) {
+	if op.ID == nil {
+		return nil, newBulkError(http.StatusBadRequest, "id is required")
+	}
+	if op.Version == nil {
+		return nil, newBulkError(http.StatusPreconditionRequired, "version is required")
+	}
+
+	var task models.Task
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(tasksWithAccess(userID, role, level)).
+		Where("tasks.id = ?", *op.ID).
+		First(&task).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, newBulkError(http.StatusNotFound, "Task not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if *op.Version != task.Version {
+		return nil, &bulkError{
+			Code:    http.StatusPreconditionFailed,
+			Message: "Task has been modified by someone else",
+			Details: gin.H{"current_version": task.Version},
+		}
+	}
+	return &task, nil
+}
+
+// bulkCheckStatusChange выполняет те же проверки смены статуса, что и PATCH /tasks/:id/status
+func bulkCheckStatusChange(tx *gorm.DB, task *models.Task, to models.TaskStatus, role string, force bool) error {
+	from := task.Status
+	if !models.CanTransition(from, to) && !(role == "admin" && force) {
+		return &bulkError{
+			Code:    http.StatusConflict,
+			Message: statusTransitionMessage(from, to),
+			Details: statusTransitionDetails(from, to),
+		}
+	}
+	return checkStatusPreconditions(tx, task, to, force)
+}
+
+// checkStatusPreconditions проверяет подзадачи и блокеры при смене статуса: завершить задачу
+// с открытыми подзадачами нельзя, начать или завершить с открытыми блокерами — только с force
+func checkStatusPreconditions(tx *gorm.DB, task *models.Task, to models.TaskStatus, force bool) error {
+	from := task.Status
+	if to == models.StatusCompleted {
+		open, err := hasOpenSubtasks(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if open {
+			return newBulkError(http.StatusConflict, "Task cannot be completed while it has pending or in-progress subtasks")
+		}
+	}
+
+	if requiresFinishedBlockers(from, to) && !force {
+		blockers, err := openBlockers(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > 0 {
+			return &bulkError{
+				Code:    http.StatusConflict,
+				Message: "Task is blocked by unfinished tasks; pass force=true to override",
+				Details: gin.H{"open_blockers": blockers},
+			}
+		}
+	}
+	return nil
+}
+
+func bulkCheckProjectAccess(tx *gorm.DB, projectID, userID uuid.UUID, role string) error {
+	allowed, err := canAddTasksToProject(tx, projectID, userID, role)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return newBulkError(http.StatusForbidden, "No permission to add tasks to this project")
+	}
+	return nil
+}
+
+func (h *TaskHandler) bulkCreate(tx *gorm.DB, op *BulkOperation, userID uuid.UUID, role string, result *BulkOperationResult) error {
+	req := op.Task
+	if req == nil {
+		return newBulkError(http.StatusBadRequest, "task is required")
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return newBulkError(http.StatusBadRequest, "title is required")
+	}
+	if req.Status != "" && !models.IsValidStatus(req.Status) {
+		return newBulkError(http.StatusBadRequest, "Invalid status value")
+	}
+	if req.Priority != "" && !models.IsValidPriority(req.Priority) {
+		return newBulkError(http.StatusBadRequest, "Invalid priority value")
+	}
+
+	var rule *recurrence.Rule
+	if req.Recurrence != "" {
+		var err error
+		if rule, err = recurrence.Parse(req.Recurrence); err != nil {
+			return newBulkError(http.StatusBadRequest, "Invalid recurrence rule: "+err.Error())
+		}
+		if req.DueDate == nil {
+			return newBulkError(http.StatusBadRequest, "A recurring task must have a due_date")
+		}
+	}
+
+	if req.ProjectID != nil {
+		if err := bulkCheckProjectAccess(tx, *req.ProjectID, userID, role); err != nil {
+			return err
+		}
+	}
+
+	task := models.Task{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      models.TaskStatus(req.Status),
+		Priority:    models.TaskPriority(req.Priority),
+		DueDate:     req.DueDate,
+		ProjectID:   req.ProjectID,
+		CreatedBy:   userID,
+	}
+	if err := tx.Create(&task).Error; err != nil {
+		return err
+	}
+	if rule != nil {
+		if _, err := startSeries(tx, &task, rule); err != nil {
+			return err
+		}
+	}
+	if err := recordTaskEvent(tx, task.ID, userID, models.TaskEventCreated, diffTasks(nil, &task)); err != nil {
+		return err
+	}
+
+	result.Code = http.StatusCreated
+	result.Task = &task
+	return nil
+}
+
+func (h *TaskHandler) bulkUpdate(tx *gorm.DB, op *BulkOperation, userID uuid.UUID, role string, result *BulkOperationResult) error {
+	if len(op.Fields) == 0 {
+		return newBulkError(http.StatusBadRequest, "fields is required")
+	}
+
+	task, err := bulkFindTask(tx, op, userID, role, accessEdit)
+	if err != nil {
+		return err
+	}
+
+	before := *task
+	if fieldErrors := applyTaskMergePatch(op.Fields, task); len(fieldErrors) > 0 {
+		return &bulkError{
+			Code:    http.StatusBadRequest,
+			Message: "Validation failed",
+			Details: gin.H{"fields": fieldErrors},
+		}
+	}
+
+	if task.Status != before.Status {
+		if err := bulkCheckStatusChange(tx, &before, task.Status, role, op.Force); err != nil {
+			return err
+		}
+	}
+	if task.ProjectID != nil && (before.ProjectID == nil || *before.ProjectID != *task.ProjectID) {
+		if err := bulkCheckProjectAccess(tx, *task.ProjectID, userID, role); err != nil {
+			return err
+		}
+	}
+
+	if changes := diffTasks(&before, task); len(changes) > 0 {
+		if err := updateTaskVersioned(tx, task, changedTaskValues(task, changes)); err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, task.ID, userID, models.TaskEventUpdated, changes); err != nil {
+			return err
+		}
+	}
+	return h.bulkFinishStatusChange(tx, &before, task, userID, result)
+}
+
+func (h *TaskHandler) bulkStatus(tx *gorm.DB, op *BulkOperation, userID uuid.UUID, role string, result *BulkOperationResult) error {
+	if !models.IsValidStatus(op.Status) {
+		return newBulkError(http.StatusBadRequest, "Invalid status value")
+	}
+
+	task, err := bulkFindTask(tx, op, userID, role, accessStatus)
+	if err != nil {
+		return err
+	}
+
+	newStatus := models.TaskStatus(op.Status)
+	if err := bulkCheckStatusChange(tx, task, newStatus, role, op.Force); err != nil {
+		return err
+	}
+
+	before := *task
+	if err := updateTaskVersioned(tx, task, map[string]interface{}{"status": newStatus}); err != nil {
+		return err
+	}
+	task.Status = newStatus
+	if changes := diffTasks(&before, task); len(changes) > 0 {
+		if err := recordTaskEvent(tx, task.ID, userID, models.TaskEventStatusChanged, changes); err != nil {
+			return err
+		}
+	}
+	return h.bulkFinishStatusChange(tx, &before, task, userID, result)
+}
+
+// bulkFinishStatusChange создаёт следующее повторение при завершении и заполняет результат
+func (h *TaskHandler) bulkFinishStatusChange(tx *gorm.DB, before, task *models.Task, userID uuid.UUID, result *BulkOperationResult) error {
+	if task.Status == models.StatusCompleted && before.Status != models.StatusCompleted {
+		next, err := spawnNextOccurrence(tx, task, userID)
+		if err != nil {
+			return err
+		}
+		result.NextOccurrence = next
+	}
+
+	result.Code = http.StatusOK
+	result.Task = task
+	return nil
+}
+
+func (h *TaskHandler) bulkDelete(tx *gorm.DB, op *BulkOperation, userID uuid.UUID, role string, result *BulkOperationResult) error {
+	task, err := bulkFindTask(tx, op, userID, role, accessEdit)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := updateTaskVersioned(tx, task, map[string]interface{}{"deleted_at": now}); err != nil {
+		return err
+	}
+	if err := setSubtreeDeletedAt(tx, task.ID, nil, &now); err != nil {
+		return err
+	}
+	if err := recordTaskEvent(tx, task.ID, userID, models.TaskEventDeleted, diffTasks(task, nil)); err != nil {
+		return err
+	}
+
+	result.Code = http.StatusOK
+	return nil
+}
+
+// runBulkOperation выполняет одну операцию в tx. Ошибки операции (*bulkError и конфликт
+// версий) записываются в result; остальные ошибки возвращаются как есть.
+func (h *TaskHandler) runBulkOperation(tx *gorm.DB, op *BulkOperation, userID uuid.UUID, role string, result *BulkOperationResult) error {
+	var err error
+	switch op.Op {
+	case bulkOpCreate:
+		err = h.bulkCreate(tx, op, userID, role, result)
+	case bulkOpUpdate:
+		err = h.bulkUpdate(tx, op, userID, role, result)
+	case bulkOpStatus:
+		err = h.bulkStatus(tx, op, userID, role, result)
+	case bulkOpDelete:
+		err = h.bulkDelete(tx, op, userID, role, result)
+	default:
+		err = newBulkError(http.StatusBadRequest, "op must be one of: create, update, status, delete")
+	}
+
+	if errors.Is(err, errVersionConflict) {
+		err = newBulkError(http.StatusPreconditionFailed, "Task has been modified by someone else")
+	}
+	if err != nil {
+		result.Success = false
+		result.Task = nil
+		result.NextOccurrence = nil
+		var opErr *bulkError
+		if errors.As(err, &opErr) {
+			result.Code = opErr.Code
+			result.Error = opErr.Message
+			result.Details = opErr.Details
+		} else {
+			result.Code = http.StatusInternalServerError
+			result.Error = "Failed to apply operation"
+		}
+		return err
+	}
+
+	result.Success = true
+	return nil
+}
+
+// BulkTasks применяет пакет операций над задачами. В режиме atomic первая ошибка
+// откатывает весь пакет; в режиме best_effort каждая операция выполняется в своей транзакции.
+func (h *TaskHandler) BulkTasks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req BulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = bulkModeAtomic
+	}
+	if req.Mode != bulkModeAtomic && req.Mode != bulkModeBestEffort {
+		respondError(c, http.StatusBadRequest, "mode must be one of: atomic, best_effort")
+		return
+	}
+	if len(req.Operations) == 0 {
+		respondError(c, http.StatusBadRequest, "operations must not be empty")
+		return
+	}
+	if len(req.Operations) > h.Config.MaxBulkOperations {
+		respondError(c, http.StatusBadRequest,
+			fmt.Sprintf("At most %d operations are allowed per request", h.Config.MaxBulkOperations))
+		return
+	}
+
+	results := make([]BulkOperationResult, len(req.Operations))
+	for i := range req.Operations {
+		results[i] = BulkOperationResult{Index: i, Op: req.Operations[i].Op}
+	}
+
+	if req.Mode == bulkModeAtomic {
+		failed := -1
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			for i := range req.Operations {
+				if err := h.runBulkOperation(tx, &req.Operations[i], userUUID, role, &results[i]); err != nil {
+					failed = i
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			if failed < 0 {
+				respondError(c, http.StatusInternalServerError, "Failed to apply operations")
+				return
+			}
+			respondErrorWithDetails(c, results[failed].Code,
+				fmt.Sprintf("Operation %d failed: %s; no changes were applied", failed, results[failed].Error),
+				gin.H{"failed": results[failed]})
+			return
+		}
+	} else {
+		for i := range req.Operations {
+			err := h.DB.Transaction(func(tx *gorm.DB) error {
+				return h.runBulkOperation(tx, &req.Operations[i], userUUID, role, &results[i])
+			})
+			// Операция прошла, но транзакция не закоммитилась: изменения откатились
+			if err != nil && results[i].Success {
+				results[i] = BulkOperationResult{
+					Index: i,
+					Op:    req.Operations[i].Op,
+					Code:  http.StatusInternalServerError,
+					Error: "Failed to apply operation",
+				}
+			}
+		}
+	}
+
+	succeeded := 0
+	for _, r := range results {
+		if r.Success {
+			succeeded++
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    results,
+		Meta: gin.H{
+			"mode":      req.Mode,
+			"total":     len(results),
+			"succeeded": succeeded,
+			"failed":    len(results) - succeeded,
+		},
+	})
+}
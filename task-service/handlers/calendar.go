@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-service/ical"
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	calendarTokenBytes = 32
+	// В ленту попадают задачи со сроком не раньше чем calendarPastDays дней назад
+	calendarPastDays = 90
+	// Длительность события VEVENT: срок задачи — момент времени, а событию нужна длина
+	calendarEventDuration = "PT30M"
+)
+
+type CalendarHandler struct {
+	DB *gorm.DB
+}
+
+func NewCalendarHandler(db *gorm.DB) *CalendarHandler {
+	return &CalendarHandler{DB: db}
+}
+
+type CalendarFeedInfo struct {
+	Enabled   bool       `json:"enabled"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// Token и URL возвращаются только при создании ленты
+	Token string `json:"token,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newCalendarToken() (string, error) {
+	buf := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// icalStatus переводит статус задачи в STATUS компонента VTODO или VEVENT
+func icalStatus(status models.TaskStatus, todo bool) string {
+	if todo {
+		switch status {
+		case models.StatusInProgress:
+			return "IN-PROCESS"
+		case models.StatusCompleted:
+			return "COMPLETED"
+		case models.StatusCancelled:
+			return "CANCELLED"
+		}
+		return "NEEDS-ACTION"
+	}
+
+	switch status {
+	case models.StatusCancelled:
+		return "CANCELLED"
+	case models.StatusPending:
+		return "TENTATIVE"
+	}
+	return "CONFIRMED"
+}
+
+// icalPriority переводит приоритет в PRIORITY: 1 — наивысший, 9 — наименьший
+func icalPriority(priority models.TaskPriority) string {
+	switch priority {
+	case models.PriorityUrgent:
+		return "1"
+	case models.PriorityHigh:
+		return "3"
+	case models.PriorityLow:
+		return "9"
+	}
+	return "5"
+}
+
+func writeTaskComponent(w *ical.Writer, task *models.Task, todo bool) {
+	component := "VEVENT"
+	if todo {
+		component = "VTODO"
+	}
+
+	w.Begin(component)
+	w.Line("UID", "task-"+task.ID.String()+"@task-service")
+	w.Time("DTSTAMP", task.UpdatedAt)
+	w.Time("LAST-MODIFIED", task.UpdatedAt)
+	w.Text("SUMMARY", task.Title)
+	if task.Description != "" {
+		w.Text("DESCRIPTION", task.Description)
+	}
+	if todo {
+		w.Time("DUE", *task.DueDate)
+	} else {
+		w.Time("DTSTART", *task.DueDate)
+		w.Line("DURATION", calendarEventDuration)
+	}
+	w.Line("STATUS", icalStatus(task.Status, todo))
+	w.Line("PRIORITY", icalPriority(task.Priority))
+	w.Line("SEQUENCE", strconv.Itoa(task.Version))
+	w.End(component)
+}
+
+// GetFeed сообщает, включена ли лента; сам токен не возвращается
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var feed models.CalendarFeed
+	err := h.DB.Where("user_id = ?", userUUID).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusOK, SuccessResponse{Success: true, Data: CalendarFeedInfo{}})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch calendar feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    CalendarFeedInfo{Enabled: true, CreatedAt: &feed.CreatedAt},
+	})
+}
+
+// RegenerateFeed создаёт новый токен ленты; прежняя ссылка перестаёт работать
+func (h *CalendarHandler) RegenerateFeed(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := newCalendarToken()
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	feed := models.CalendarFeed{
+		UserID:    userUUID,
+		TokenHash: hashCalendarToken(token),
+		CreatedAt: time.Now(),
+	}
+	err = h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "created_at"}),
+	}).Create(&feed).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to save calendar feed")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data: CalendarFeedInfo{
+			Enabled:   true,
+			CreatedAt: &feed.CreatedAt,
+			Token:     token,
+			URL:       "/calendar/" + token + ".ics",
+		},
+	})
+}
+
+// DeleteFeed отключает ленту
+func (h *CalendarHandler) DeleteFeed(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Where("user_id = ?", userUUID).Delete(&models.CalendarFeed{}).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete calendar feed")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Calendar feed disabled"},
+	})
+}
+
+// GetCalendar отдаёт ленту сроков задач в формате iCalendar. Доступ — по секретному токену
+// в пути, без Authorization, чтобы ссылку можно было добавить в календарное приложение.
+// ?kind=todo выдаёт задачи как VTODO вместо VEVENT.
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("file"), ".ics")
+	if !ok || token == "" {
+		respondError(c, http.StatusNotFound, "Calendar not found")
+		return
+	}
+
+	todo := c.Query("kind") == "todo"
+
+	var feed models.CalendarFeed
+	err := h.DB.Where("token_hash = ?", hashCalendarToken(token)).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, "Calendar not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch calendar")
+		return
+	}
+
+	// Лента показывает задачи, видимые пользователю как участнику, без прав администратора
+	var tasks []models.Task
+	err = h.DB.Scopes(visibleTasks(feed.UserID, "")).
+		Where("tasks.due_date IS NOT NULL AND tasks.due_date >= ?", time.Now().AddDate(0, 0, -calendarPastDays)).
+		Order("tasks.due_date").
+		Find(&tasks).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch tasks")
+		return
+	}
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+	c.Status(http.StatusOK)
+
+	w := ical.NewWriter(c.Writer)
+	w.Begin("VCALENDAR")
+	w.Line("VERSION", "2.0")
+	w.Line("PRODID", "-//Task Manager//Task Service//EN")
+	w.Line("CALSCALE", "GREGORIAN")
+	w.Line("METHOD", "PUBLISH")
+	w.Text("X-WR-CALNAME", "Tasks")
+	for i := range tasks {
+		writeTaskComponent(w, &tasks[i], todo)
+	}
+	w.End("VCALENDAR")
+	if err := w.Flush(); err != nil {
+		log.Printf("Calendar write failed for user %s: %v", feed.UserID, err)
+	}
+}
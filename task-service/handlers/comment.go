@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxCommentLength = 10000
+
+type CreateCommentRequest struct {
+	Body     string     `json:"body" binding:"required"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+func validateCommentBody(body string) (string, string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", "Comment body cannot be empty"
+	}
+	if len(body) > maxCommentLength {
+		return "", "Comment body is too long"
+	}
+	return body, ""
+}
+
+// findComment загружает комментарий задачи. При ошибке ответ уже отправлен.
+func (h *TaskHandler) findComment(c *gin.Context, taskID uuid.UUID) (*models.TaskComment, bool) {
+	commentUUID, ok := uuidParam(c, "commentId", "comment")
+	if !ok {
+		return nil, false
+	}
+
+	var comment models.TaskComment
+	if err := h.DB.Where("id = ? AND task_id = ?", commentUUID, taskID).First(&comment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Comment not found")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch comment")
+		return nil, false
+	}
+	return &comment, true
+}
+
+// GetComments возвращает комментарии верхнего уровня с ответами, от старых к новым
+func (h *TaskHandler) GetComments(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	comments := []models.TaskComment{}
+	err := h.DB.Preload("Replies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).
+		Where("task_id = ? AND parent_id IS NULL", task.ID).
+		Order("created_at").
+		Find(&comments).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    comments,
+	})
+}
+
+func (h *TaskHandler) CreateComment(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	body, msg := validateCommentBody(req.Body)
+	if msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	// Комментировать может любой, кто видит задачу
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	// Допускается только один уровень вложенности
+	if req.ParentID != nil {
+		var parent models.TaskComment
+		if err := h.DB.Where("id = ? AND task_id = ?", *req.ParentID, task.ID).First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(c, http.StatusBadRequest, "Parent comment not found")
+				return
+			}
+			respondError(c, http.StatusInternalServerError, "Failed to fetch parent comment")
+			return
+		}
+		if parent.ParentID != nil {
+			respondError(c, http.StatusBadRequest, "Replies can only be added to top-level comments")
+			return
+		}
+	}
+
+	comment := models.TaskComment{
+		TaskID:   task.ID,
+		ParentID: req.ParentID,
+		AuthorID: userUUID,
+		Body:     body,
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		return queueCommentNotification(tx, &comment)
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create comment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    comment,
+	})
+}
+
+// UpdateComment меняет текст комментария; предыдущий текст сохраняется в истории
+func (h *TaskHandler) UpdateComment(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	body, msg := validateCommentBody(req.Body)
+	if msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.findComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != userUUID {
+		respondError(c, http.StatusForbidden, "Only the author can edit a comment")
+		return
+	}
+
+	if comment.Body == body {
+		c.JSON(http.StatusOK, SuccessResponse{
+			Success: true,
+			Data:    comment,
+		})
+		return
+	}
+
+	now := time.Now()
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		revision := models.TaskCommentRevision{
+			CommentID: comment.ID,
+			Body:      comment.Body,
+			EditedBy:  userUUID,
+			EditedAt:  now,
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		comment.Body = body
+		comment.EditedAt = &now
+		return tx.Model(comment).Updates(map[string]interface{}{
+			"body":      comment.Body,
+			"edited_at": comment.EditedAt,
+		}).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to update comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    comment,
+	})
+}
+
+// DeleteComment удаляет комментарий вместе с ответами; доступно автору и администратору
+func (h *TaskHandler) DeleteComment(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.findComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != userUUID && role != "admin" {
+		respondError(c, http.StatusForbidden, "Only the author or an admin can delete a comment")
+		return
+	}
+
+	if err := h.DB.Delete(comment).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete comment")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Comment deleted successfully"},
+	})
+}
+
+func (h *TaskHandler) GetCommentHistory(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	comment, ok := h.findComment(c, task.ID)
+	if !ok {
+		return
+	}
+
+	revisions := []models.TaskCommentRevision{}
+	if err := h.DB.Where("comment_id = ?", comment.ID).Order("edited_at").Find(&revisions).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch comment history")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    revisions,
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errVersionConflict — задачу изменили после того, как клиент получил её версию
+var errVersionConflict = errors.New("task version conflict")
+
+func taskETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+func setTaskETag(c *gin.Context, task *models.Task) {
+	c.Header("ETag", taskETag(task.Version))
+}
+
+// etagMatches проверяет значение If-Match: список ETag через запятую или "*"
+func etagMatches(header string, version int) bool {
+	etag := taskETag(version)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// requireIfMatch возвращает заголовок If-Match; без него отвечает 428. При ошибке ответ уже отправлен.
+func requireIfMatch(c *gin.Context) (string, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		respondError(c, http.StatusPreconditionRequired, "If-Match header with the task ETag is required")
+		return "", false
+	}
+	return header, true
+}
+
+// checkIfMatch сверяет If-Match с версией загруженной задачи. При ошибке ответ уже отправлен.
+func (h *TaskHandler) checkIfMatch(c *gin.Context, task *models.Task) bool {
+	header, ok := requireIfMatch(c)
+	if !ok {
+		return false
+	}
+	if !etagMatches(header, task.Version) {
+		h.respondVersionConflict(c, task.ID)
+		return false
+	}
+	return true
+}
+
+// updateTaskVersioned обновляет задачу, только если её версия не изменилась с момента чтения,
+// и увеличивает версию. Иначе возвращает errVersionConflict.
+func updateTaskVersioned(tx *gorm.DB, task *models.Task, values map[string]interface{}) error {
+	now := time.Now()
+	values["version"] = gorm.Expr("version + 1")
+	values["updated_at"] = now
+	result := tx.Model(&models.Task{}).
+		Where("id = ? AND version = ?", task.ID, task.Version).
+		Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errVersionConflict
+	}
+	task.Version++
+	task.UpdatedAt = now
+	return nil
+}
+
+// respondVersionConflict отвечает 412 и возвращает актуальное состояние задачи в details
+func (h *TaskHandler) respondVersionConflict(c *gin.Context, taskID uuid.UUID) {
+	var current models.Task
+	if err := h.DB.Preload("Assignees").Where("id = ?", taskID).First(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, &current); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	setTaskETag(c, &current)
+	respondErrorWithDetails(c, http.StatusPreconditionFailed, "Task has been modified by someone else", current)
+}
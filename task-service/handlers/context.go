@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// currentUser возвращает ID и роль пользователя, установленные AuthMiddleware.
+// При ошибке ответ уже отправлен клиенту и возвращается ok == false.
+func currentUser(c *gin.Context) (uuid.UUID, string, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, "User not authenticated")
+		return uuid.Nil, "", false
+	}
+
+	userIDStr, ok := userID.(string)
+	if !ok {
+		respondError(c, http.StatusBadRequest, "Invalid user ID format")
+		return uuid.Nil, "", false
+	}
+
+	userUUID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, "", false
+	}
+
+	return userUUID, c.GetString("role"), true
+}
+
+// uuidParam разбирает UUID из параметра пути; name используется в тексте ошибки
+func uuidParam(c *gin.Context, param, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid "+name+" ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondError(c *gin.Context, code int, message string) {
+	c.JSON(code, ErrorResponse{
+		Success: false,
+		Error:   message,
+		Code:    code,
+	})
+}
+
+func respondErrorWithDetails(c *gin.Context, code int, message string, details interface{}) {
+	c.JSON(code, ErrorResponse{
+		Success: false,
+		Error:   message,
+		Code:    code,
+		Details: details,
+	})
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxGraphTasks = 500
+
+var errDependencyCycle = errors.New("dependency would create a cycle")
+
+type AddDependencyRequest struct {
+	BlockerID uuid.UUID `json:"blocker_id" binding:"required"`
+}
+
+type TaskDependencies struct {
+	BlockedBy []models.Task `json:"blocked_by"`
+	Blocks    []models.Task `json:"blocks"`
+}
+
+type DependencyNode struct {
+	ID       uuid.UUID           `json:"id"`
+	Title    string              `json:"title"`
+	Status   models.TaskStatus   `json:"status"`
+	Priority models.TaskPriority `json:"priority"`
+	DueDate  *time.Time          `json:"due_date,omitempty"`
+}
+
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+	// Самая длинная цепочка незавершённых задач (завершённые и отменённые имеют вес 0)
+	CriticalPath       []uuid.UUID `json:"critical_path"`
+	CriticalPathLength int         `json:"critical_path_length"`
+}
+
+type DependencyEdge struct {
+	BlockerID uuid.UUID `json:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id"`
+}
+
+type BlockerInfo struct {
+	ID     uuid.UUID         `json:"id"`
+	Title  string            `json:"title"`
+	Status models.TaskStatus `json:"status"`
+}
+
+// openBlockers возвращает блокеры задачи, которые ещё не завершены и не отменены
+func openBlockers(db *gorm.DB, taskID uuid.UUID) ([]BlockerInfo, error) {
+	var blockers []BlockerInfo
+	err := db.Model(&models.Task{}).
+		Select("tasks.id, tasks.title, tasks.status").
+		Joins("JOIN task_schema.task_dependencies d ON d.blocker_id = tasks.id").
+		Where("d.blocked_id = ? AND tasks.status NOT IN ?", taskID,
+			[]models.TaskStatus{models.StatusCompleted, models.StatusCancelled}).
+		Scan(&blockers).Error
+	return blockers, err
+}
+
+// requiresFinishedBlockers — переход в in_progress или completed требует завершённых блокеров
+func requiresFinishedBlockers(from, to models.TaskStatus) bool {
+	return from != to && (to == models.StatusInProgress || to == models.StatusCompleted)
+}
+
+// checkBlockersFinished не даёт начать или завершить задачу с открытыми блокерами.
+// При ошибке ответ уже отправлен.
+func (h *TaskHandler) checkBlockersFinished(c *gin.Context, task *models.Task) bool {
+	blockers, err := openBlockers(h.DB, task.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check task dependencies")
+		return false
+	}
+	if len(blockers) > 0 {
+		respondErrorWithDetails(c, http.StatusConflict,
+			"Task is blocked by unfinished tasks; pass force=true to override",
+			gin.H{"open_blockers": blockers})
+		return false
+	}
+	return true
+}
+
+// createsCycle проверяет, зависит ли blocker (транзитивно) от blocked
+func createsCycle(tx *gorm.DB, blockerID, blockedID uuid.UUID) (bool, error) {
+	var exists bool
+	err := tx.Raw(`
+		WITH RECURSIVE downstream AS (
+			SELECT blocked_id FROM task_schema.task_dependencies WHERE blocker_id = ?
+			UNION
+			SELECT d.blocked_id
+			FROM task_schema.task_dependencies d
+			JOIN downstream ds ON d.blocker_id = ds.blocked_id
+		)
+		SELECT EXISTS (SELECT 1 FROM downstream WHERE blocked_id = ?)`, blockedID, blockerID).
+		Scan(&exists).Error
+	return exists, err
+}
+
+func (h *TaskHandler) GetTaskDependencies(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	deps := TaskDependencies{BlockedBy: []models.Task{}, Blocks: []models.Task{}}
+
+	err := h.DB.Scopes(visibleTasks(userUUID, role)).
+		Joins("JOIN task_schema.task_dependencies d ON d.blocker_id = tasks.id").
+		Where("d.blocked_id = ?", task.ID).
+		Find(&deps.BlockedBy).Error
+	if err == nil {
+		err = h.DB.Scopes(visibleTasks(userUUID, role)).
+			Joins("JOIN task_schema.task_dependencies d ON d.blocked_id = tasks.id").
+			Where("d.blocker_id = ?", task.ID).
+			Find(&deps.Blocks).Error
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task dependencies")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    deps,
+	})
+}
+
+// AddTaskDependency помечает задачу :id как заблокированную задачей blocker_id
+func (h *TaskHandler) AddTaskDependency(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+	if req.BlockerID == taskUUID {
+		respondError(c, http.StatusBadRequest, "A task cannot block itself")
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	var blocker models.Task
+	if err := h.DB.Scopes(visibleTasks(userUUID, role)).Where("tasks.id = ?", req.BlockerID).First(&blocker).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Blocker task not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch blocker task")
+		return
+	}
+
+	dependency := models.TaskDependency{
+		BlockerID: blocker.ID,
+		BlockedID: task.ID,
+		CreatedBy: userUUID,
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Сериализуем изменения графа, чтобы параллельные запросы не создали цикл
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('task_schema.task_dependencies'))").Error; err != nil {
+			return err
+		}
+
+		cycle, err := createsCycle(tx, blocker.ID, task.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return errDependencyCycle
+		}
+
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dependency).Error
+	})
+	if errors.Is(err, errDependencyCycle) {
+		respondError(c, http.StatusConflict, "Dependency would create a cycle")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to add dependency")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    dependency,
+	})
+}
+
+func (h *TaskHandler) RemoveTaskDependency(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	blockerUUID, ok := uuidParam(c, "blockerId", "blocker task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("blocker_id = ? AND blocked_id = ?", blockerUUID, task.ID).Delete(&models.TaskDependency{})
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to remove dependency")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Dependency not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Dependency removed successfully"},
+	})
+}
+
+// GetDependencyGraph возвращает граф зависимостей и критический путь для набора задач:
+// ?ids=a,b,c или ?project_id=...
+func (h *TaskHandler) GetDependencyGraph(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.Task{}).Scopes(visibleTasks(userUUID, role))
+
+	switch {
+	case c.Query("ids") != "":
+		var ids []uuid.UUID
+		for _, raw := range strings.Split(c.Query("ids"), ",") {
+			id, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid task ID: "+raw)
+				return
+			}
+			ids = append(ids, id)
+		}
+		db = db.Where("tasks.id IN ?", ids)
+	case c.Query("project_id") != "":
+		projectID, err := uuid.Parse(c.Query("project_id"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid project ID")
+			return
+		}
+		db = db.Where("tasks.project_id = ?", projectID)
+	default:
+		respondError(c, http.StatusBadRequest, "Either ids or project_id is required")
+		return
+	}
+
+	var nodes []DependencyNode
+	if err := db.Select("tasks.id, tasks.title, tasks.status, tasks.priority, tasks.due_date").
+		Order("tasks.created_at").
+		Limit(maxGraphTasks + 1).
+		Scan(&nodes).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch tasks")
+		return
+	}
+	if len(nodes) > maxGraphTasks {
+		respondError(c, http.StatusBadRequest, "Too many tasks for a dependency graph")
+		return
+	}
+
+	ids := make([]uuid.UUID, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+
+	edges := []DependencyEdge{}
+	if len(ids) > 0 {
+		if err := h.DB.Model(&models.TaskDependency{}).
+			Select("blocker_id, blocked_id").
+			Where("blocker_id IN ? AND blocked_id IN ?", ids, ids).
+			Scan(&edges).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to fetch dependencies")
+			return
+		}
+	}
+
+	graph := DependencyGraph{Nodes: nodes, Edges: edges}
+	if graph.Nodes == nil {
+		graph.Nodes = []DependencyNode{}
+	}
+	graph.CriticalPath, graph.CriticalPathLength = criticalPath(graph.Nodes, edges)
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    graph,
+	})
+}
+
+// criticalPath ищет самый длинный путь в ациклическом графе (алгоритм Кана).
+// Вес узла — 1 для незавершённых задач и 0 для завершённых или отменённых.
+func criticalPath(nodes []DependencyNode, edges []DependencyEdge) ([]uuid.UUID, int) {
+	weight := make(map[uuid.UUID]int, len(nodes))
+	inDegree := make(map[uuid.UUID]int, len(nodes))
+	for _, n := range nodes {
+		if n.Status != models.StatusCompleted && n.Status != models.StatusCancelled {
+			weight[n.ID] = 1
+		}
+		inDegree[n.ID] = 0
+	}
+
+	next := make(map[uuid.UUID][]uuid.UUID)
+	for _, e := range edges {
+		next[e.BlockerID] = append(next[e.BlockerID], e.BlockedID)
+		inDegree[e.BlockedID]++
+	}
+
+	queue := make([]uuid.UUID, 0, len(nodes))
+	for _, n := range nodes {
+		if inDegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+
+	dist := make(map[uuid.UUID]int, len(nodes))
+	prev := make(map[uuid.UUID]uuid.UUID, len(nodes))
+	var end uuid.UUID
+	best := -1
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		dist[id] += weight[id]
+		if dist[id] > best {
+			best, end = dist[id], id
+		}
+
+		for _, to := range next[id] {
+			if dist[id] > dist[to] {
+				dist[to] = dist[id]
+				prev[to] = id
+			}
+			inDegree[to]--
+			if inDegree[to] == 0 {
+				queue = append(queue, to)
+			}
+		}
+	}
+
+	if best <= 0 {
+		return []uuid.UUID{}, 0
+	}
+
+	path := []uuid.UUID{end}
+	for {
+		p, ok := prev[path[0]]
+		if !ok {
+			break
+		}
+		path = append([]uuid.UUID{p}, path...)
+	}
+	return path, best
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"task-service/models"
+
+	"gorm.io/gorm"
+)
+
+// enrichTasks заполняет вычисляемые поля задач (метки, прогресс подзадач)
+func enrichTasks(db *gorm.DB, tasks ...*models.Task) error {
+	if err := attachLabels(db, tasks...); err != nil {
+		return err
+	}
+	return attachProgress(db, tasks...)
+}
+
+func enrichTaskList(db *gorm.DB, tasks []models.Task) error {
+	ptrs := make([]*models.Task, len(tasks))
+	for i := range tasks {
+		ptrs[i] = &tasks[i]
+	}
+	return enrichTasks(db, ptrs...)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Задачи выгружаются порциями, чтобы большой экспорт не держать в памяти целиком
+const exportBatchSize = 500
+
+// Колонки CSV-экспорта; импорт понимает файл экспорта и пропускает лишние колонки
+var taskCSVColumns = []string{
+	"id", "external_id", "title", "description", "status", "priority", "due_date",
+	"project_id", "parent_id", "assignees", "labels", "created_by", "created_at", "updated_at",
+}
+
+// taskWriter пишет задачи в одном из форматов экспорта
+type taskWriter interface {
+	Begin() error
+	Write(task *models.Task) error
+	End() error
+}
+
+type csvTaskWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvTaskWriter) Begin() error {
+	return cw.w.Write(taskCSVColumns)
+}
+
+func (cw *csvTaskWriter) Write(task *models.Task) error {
+	assignees := make([]string, len(task.Assignees))
+	for i, a := range task.Assignees {
+		assignees[i] = a.UserID.String()
+	}
+	labels := make([]string, len(task.Labels))
+	for i, l := range task.Labels {
+		labels[i] = l.Name
+	}
+
+	return cw.w.Write([]string{
+		task.ID.String(),
+		stringValue(task.ExternalID),
+		task.Title,
+		task.Description,
+		string(task.Status),
+		string(task.Priority),
+		formatExportTime(task.DueDate),
+		uuidValue(task.ProjectID),
+		uuidValue(task.ParentID),
+		strings.Join(assignees, ";"),
+		strings.Join(labels, ";"),
+		task.CreatedBy.String(),
+		formatExportTime(&task.CreatedAt),
+		formatExportTime(&task.UpdatedAt),
+	})
+}
+
+func (cw *csvTaskWriter) End() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonTaskWriter пишет JSON-массив (array == true) или NDJSON — по объекту на строку
+type jsonTaskWriter struct {
+	w     io.Writer
+	array bool
+	count int
+}
+
+func (jw *jsonTaskWriter) Begin() error {
+	if jw.array {
+		_, err := io.WriteString(jw.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (jw *jsonTaskWriter) Write(task *models.Task) error {
+	raw, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	if jw.array && jw.count > 0 {
+		if _, err := io.WriteString(jw.w, ","); err != nil {
+			return err
+		}
+	}
+	if !jw.array {
+		raw = append(raw, '\n')
+	}
+	jw.count++
+	_, err = jw.w.Write(raw)
+	return err
+}
+
+func (jw *jsonTaskWriter) End() error {
+	if jw.array {
+		_, err := io.WriteString(jw.w, "]\n")
+		return err
+	}
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func uuidValue(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// ExportTasks выгружает задачи в CSV, JSON или NDJSON. Фильтры, сортировка и view — как у GET /tasks;
+// limit и cursor не учитываются, выгружаются все подходящие задачи.
+func (h *TaskHandler) ExportTasks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	query, _, ok := taskListQueryWithView(c, h.DB, userUUID, false)
+	if !ok {
+		return
+	}
+	query.Limit = exportBatchSize
+	query.Cursor = nil
+
+	format := c.DefaultQuery("format", "csv")
+	var writer taskWriter
+	var contentType string
+	switch format {
+	case "csv":
+		writer, contentType = &csvTaskWriter{w: csv.NewWriter(c.Writer)}, "text/csv; charset=utf-8"
+	case "json":
+		writer, contentType = &jsonTaskWriter{w: c.Writer, array: true}, "application/json"
+	case "ndjson":
+		writer, contentType = &jsonTaskWriter{w: c.Writer}, "application/x-ndjson"
+	default:
+		respondError(c, http.StatusBadRequest, "format must be one of: csv, json, ndjson")
+		return
+	}
+
+	base := query.filters(h.DB.Model(&models.Task{}).Scopes(visibleTasks(userUUID, role)))
+
+	// Первая порция читается до отправки заголовков, чтобы ошибку БД можно было вернуть кодом 500
+	var batch []models.Task
+	if err := query.page(base.Session(&gorm.Session{})).Preload("Assignees").Find(&batch).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch tasks")
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks-%s.%s"`, time.Now().UTC().Format("20060102"), format))
+	c.Status(http.StatusOK)
+
+	// После начала ответа код уже не изменить: ошибка обрывает выгрузку и пишется в лог
+	if err := writer.Begin(); err != nil {
+		log.Printf("Task export failed: %v", err)
+		return
+	}
+	for {
+		more := len(batch) > query.Limit
+		if more {
+			batch = batch[:query.Limit]
+		}
+		if err := enrichTaskList(h.DB, batch); err != nil {
+			log.Printf("Task export failed: %v", err)
+			return
+		}
+		for i := range batch {
+			if err := writer.Write(&batch[i]); err != nil {
+				log.Printf("Task export failed: %v", err)
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		if !more {
+			break
+		}
+		query.Cursor = query.cursorAt(batch[len(batch)-1])
+		batch = nil
+		if err := query.page(base.Session(&gorm.Session{})).Preload("Assignees").Find(&batch).Error; err != nil {
+			log.Printf("Task export failed: %v", err)
+			return
+		}
+	}
+	if err := writer.End(); err != nil {
+		log.Printf("Task export failed: %v", err)
+	}
+}
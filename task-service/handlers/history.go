@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// taskSnapshot возвращает отслеживаемые поля задачи; nil — задача отсутствует
+func taskSnapshot(t *models.Task) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+
+	snapshot := map[string]interface{}{
+		"title":       t.Title,
+		"description": t.Description,
+		"status":      string(t.Status),
+		"priority":    string(t.Priority),
+	}
+	if t.DueDate != nil {
+		snapshot["due_date"] = t.DueDate.UTC().Format(time.RFC3339Nano)
+	}
+	if t.ProjectID != nil {
+		snapshot["project_id"] = t.ProjectID.String()
+	}
+	if t.ParentID != nil {
+		snapshot["parent_id"] = t.ParentID.String()
+	}
+	return snapshot
+}
+
+var trackedTaskFields = []string{"title", "description", "status", "priority", "due_date", "project_id", "parent_id"}
+
+// diffTasks сравнивает две версии задачи. before == nil — создание, after == nil — удаление.
+func diffTasks(before, after *models.Task) models.FieldChanges {
+	old, cur := taskSnapshot(before), taskSnapshot(after)
+	changes := models.FieldChanges{}
+	for _, field := range trackedTaskFields {
+		if old[field] != cur[field] {
+			changes[field] = models.FieldChange{Old: old[field], New: cur[field]}
+		}
+	}
+	return changes
+}
+
+// recordTaskEvent пишет событие в журнал. Вызывается в той же транзакции, что и изменение задачи.
+// Смена статуса и срока заодно ставится в очередь уведомлений наблюдателям,
+// а событие — в очередь доставки вебхуков и в outbox доменных событий.
+func recordTaskEvent(tx *gorm.DB, taskID, actorID uuid.UUID, action models.TaskEventAction, changes models.FieldChanges) error {
+	return recordTaskEventForUsers(tx, taskID, actorID, action, changes, nil)
+}
+
+// recordTaskEventForUsers — recordTaskEvent для назначения и снятия исполнителей:
+// userIDs (кого назначили или сняли) попадают в доменное событие
+func recordTaskEventForUsers(tx *gorm.DB, taskID, actorID uuid.UUID, action models.TaskEventAction, changes models.FieldChanges, userIDs []uuid.UUID) error {
+	event := models.TaskEvent{
+		TaskID:  taskID,
+		ActorID: actorID,
+		Action:  action,
+		Changes: changes,
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return err
+	}
+	if action == models.TaskEventUpdated || action == models.TaskEventStatusChanged {
+		if err := queueWatcherNotifications(tx, taskID, actorID, changes); err != nil {
+			return err
+		}
+	}
+	if err := queueWebhookDeliveries(tx, &event); err != nil {
+		return err
+	}
+	return queueOutboxEvent(tx, event.ID, models.DomainEventType(action), taskID, actorID, changes, userIDs)
+}
+
+// assigneeChanges — изменение списка исполнителей для журнала; ID отсортированы
+func assigneeChanges(before, after []uuid.UUID) models.FieldChanges {
+	ids := func(list []uuid.UUID) []string {
+		out := make([]string, len(list))
+		for i, id := range list {
+			out[i] = id.String()
+		}
+		sort.Strings(out)
+		return out
+	}
+	return models.FieldChanges{"assignees": {Old: ids(before), New: ids(after)}}
+}
+
+// GetTaskHistory возвращает журнал изменений задачи, от старых событий к новым
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	// История доступна и для задач в корзине
+	var task models.Task
+	err := h.DB.Unscoped().
+		Scopes(visibleTasks(userUUID, role)).
+		Where("tasks.id = ?", taskUUID).
+		First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Task not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+
+	events := []models.TaskEvent{}
+	if err := h.DB.Where("task_id = ?", task.ID).Order("created_at, id").Find(&events).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task history")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    events,
+	})
+}
@@ -0,0 +1,565 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportBodyBytes  = 10 << 20
+	maxExternalIDLength = 255
+
+	importActionCreate = "create"
+	importActionUpdate = "update"
+	importActionSkip   = "skip"
+)
+
+// importRow — строка импорта до валидации; значения приходят строками из CSV или JSON.
+// present — какие колонки CSV или ключи JSON были в строке: при обновлении задачи
+// меняются только они.
+type importRow struct {
+	ExternalID  string `json:"external_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Priority    string `json:"priority"`
+	DueDate     string `json:"due_date"`
+	ProjectID   string `json:"project_id"`
+
+	present map[string]bool
+}
+
+func (r *importRow) UnmarshalJSON(data []byte) error {
+	type plain importRow
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	r.present = make(map[string]bool, len(keys))
+	for key := range keys {
+		r.present[strings.ToLower(key)] = true
+	}
+	return nil
+}
+
+func (r importRow) has(field string) bool {
+	return r.present[field]
+}
+
+type ImportRowResult struct {
+	// Row — номер строки данных, начиная с 1 (заголовок CSV не считается)
+	Row        int        `json:"row"`
+	ExternalID string     `json:"external_id,omitempty"`
+	Action     string     `json:"action,omitempty"`
+	TaskID     *uuid.UUID `json:"task_id,omitempty"`
+	// Changes — что изменит строка с action=update
+	Changes models.FieldChanges `json:"changes,omitempty"`
+	// NextOccurrenceID — повторение, созданное при завершении повторяющейся задачи
+	NextOccurrenceID *uuid.UUID        `json:"next_occurrence_id,omitempty"`
+	Errors           map[string]string `json:"errors,omitempty"`
+}
+
+// importRowError — строка, которую нельзя применить из-за состояния задач на момент записи
+type importRowError struct {
+	Row     int
+	Field   string
+	Message string
+}
+
+func (e *importRowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// importedTask — провалидированная строка, готовая к записи
+type importedTask struct {
+	result   *ImportRowResult
+	task     models.Task
+	existing *models.Task
+}
+
+func readCSVImportRows(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("CSV header must contain a title column")
+	}
+
+	get := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	present := func(record []string) map[string]bool {
+		fields := make(map[string]bool, len(columns))
+		for name, i := range columns {
+			if i < len(record) {
+				fields[name] = true
+			}
+		}
+		return fields
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, importRow{
+			ExternalID:  get(record, "external_id"),
+			Title:       get(record, "title"),
+			Description: get(record, "description"),
+			Status:      get(record, "status"),
+			Priority:    get(record, "priority"),
+			DueDate:     get(record, "due_date"),
+			ProjectID:   get(record, "project_id"),
+			present:     present(record),
+		})
+	}
+}
+
+func readJSONImportRows(r io.Reader) ([]importRow, error) {
+	var rows []importRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func readNDJSONImportRows(r io.Reader) ([]importRow, error) {
+	var rows []importRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportBodyBytes)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row importRow
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// importFormat определяет формат по параметру format или по Content-Type
+func importFormat(c *gin.Context) string {
+	if v := c.Query("format"); v != "" {
+		return v
+	}
+	switch c.ContentType() {
+	case "application/json":
+		return "json"
+	case "application/x-ndjson":
+		return "ndjson"
+	}
+	return "csv"
+}
+
+// validateImportRow проверяет строку так же, как POST /tasks; ошибки собираются по полям
+func validateImportRow(row importRow, userID uuid.UUID) (models.Task, map[string]string) {
+	fieldErrors := map[string]string{}
+	task := models.Task{
+		Description: row.Description,
+		Status:      models.StatusPending,
+		Priority:    models.PriorityMedium,
+		CreatedBy:   userID,
+	}
+
+	if externalID := strings.TrimSpace(row.ExternalID); externalID != "" {
+		if len(externalID) > maxExternalIDLength {
+			fieldErrors["external_id"] = "must be at most 255 characters"
+		}
+		task.ExternalID = &externalID
+	}
+
+	task.Title = strings.TrimSpace(row.Title)
+	if task.Title == "" {
+		fieldErrors["title"] = "is required"
+	} else if len([]rune(task.Title)) > maxTaskTitleLength {
+		fieldErrors["title"] = "must be at most 255 characters"
+	}
+
+	if v := strings.TrimSpace(row.Status); v != "" {
+		if !models.IsValidStatus(v) {
+			fieldErrors["status"] = "must be one of: pending, in_progress, completed, cancelled"
+		}
+		task.Status = models.TaskStatus(v)
+	}
+	if v := strings.TrimSpace(row.Priority); v != "" {
+		if !models.IsValidPriority(v) {
+			fieldErrors["priority"] = "must be one of: low, medium, high, urgent"
+		}
+		task.Priority = models.TaskPriority(v)
+	}
+
+	if v := strings.TrimSpace(row.DueDate); v != "" {
+		due, err := parseQueryTime(v)
+		if err != nil {
+			fieldErrors["due_date"] = "must be an RFC3339 timestamp or YYYY-MM-DD"
+		} else {
+			task.DueDate = &due
+		}
+	}
+
+	if v := strings.TrimSpace(row.ProjectID); v != "" {
+		projectID, err := uuid.Parse(v)
+		if err != nil {
+			fieldErrors["project_id"] = "must be a project UUID"
+		} else {
+			task.ProjectID = &projectID
+		}
+	}
+
+	return task, fieldErrors
+}
+
+// mergeImportRow накладывает строку на существующую задачу: меняются только поля, которые
+// были в строке. Пустые status и priority оставляют прежние значения, пустые description,
+// due_date и project_id очищают поле.
+func mergeImportRow(existing, task *models.Task, row importRow) models.Task {
+	merged := *existing
+	merged.Title = task.Title
+	if row.has("description") {
+		merged.Description = task.Description
+	}
+	if strings.TrimSpace(row.Status) != "" {
+		merged.Status = task.Status
+	}
+	if strings.TrimSpace(row.Priority) != "" {
+		merged.Priority = task.Priority
+	}
+	if row.has("due_date") {
+		merged.DueDate = task.DueDate
+	}
+	if row.has("project_id") {
+		merged.ProjectID = task.ProjectID
+	}
+	return merged
+}
+
+// canEditImportedTask проверяет в транзакции, что пользователь всё ещё может изменять задачу
+func canEditImportedTask(tx *gorm.DB, taskID, userID uuid.UUID, role string) (bool, error) {
+	var count int64
+	err := tx.Unscoped().Model(&models.Task{}).
+		Scopes(editableTasks(userID, role)).
+		Where("tasks.id = ?", taskID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ImportTasks импортирует задачи из CSV, JSON-массива или NDJSON. Строки с external_id,
+// который уже есть у задач пользователя, пропускаются или обновляют задачу (on_duplicate=update).
+// Если хотя бы одна строка не прошла проверку, ничего не записывается; dry_run=true только
+// проверяет файл и показывает, что было бы сделано.
+func (h *TaskHandler) ImportTasks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	onDuplicate := c.DefaultQuery("on_duplicate", importActionSkip)
+	if onDuplicate != importActionSkip && onDuplicate != importActionUpdate {
+		respondError(c, http.StatusBadRequest, "on_duplicate must be one of: skip, update")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	var rows []importRow
+	var err error
+	switch format := importFormat(c); format {
+	case "csv":
+		rows, err = readCSVImportRows(body)
+	case "json":
+		rows, err = readJSONImportRows(body)
+	case "ndjson":
+		rows, err = readNDJSONImportRows(body)
+	default:
+		respondError(c, http.StatusBadRequest, "format must be one of: csv, json, ndjson")
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, http.StatusRequestEntityTooLarge, "Import file is too large")
+			return
+		}
+		respondError(c, http.StatusBadRequest, "Failed to parse import file: "+err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		respondError(c, http.StatusBadRequest, "Import file has no rows")
+		return
+	}
+	if len(rows) > h.Config.MaxImportRows {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("At most %d rows can be imported at once", h.Config.MaxImportRows))
+		return
+	}
+
+	// Уже существующие задачи пользователя с теми же external_id (включая корзину)
+	var externalIDs []string
+	for _, row := range rows {
+		if v := strings.TrimSpace(row.ExternalID); v != "" {
+			externalIDs = append(externalIDs, v)
+		}
+	}
+	existing := map[string]*models.Task{}
+	if len(externalIDs) > 0 {
+		var found []models.Task
+		err := h.DB.Unscoped().
+			Where("created_by = ? AND external_id IN ?", userUUID, externalIDs).
+			Find(&found).Error
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to check existing tasks")
+			return
+		}
+		for i := range found {
+			existing[*found[i].ExternalID] = &found[i]
+		}
+	}
+
+	// Обновлять можно только задачи, которые пользователь может изменять: автор мог потерять
+	// права на задачу проекта
+	editable := map[uuid.UUID]bool{}
+	if onDuplicate == importActionUpdate && len(existing) > 0 {
+		ids := make([]uuid.UUID, 0, len(existing))
+		for _, task := range existing {
+			ids = append(ids, task.ID)
+		}
+		var editableIDs []uuid.UUID
+		err := h.DB.Unscoped().Model(&models.Task{}).
+			Scopes(editableTasks(userUUID, role)).
+			Where("tasks.id IN ?", ids).
+			Pluck("tasks.id", &editableIDs).Error
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to check existing tasks")
+			return
+		}
+		for _, id := range editableIDs {
+			editable[id] = true
+		}
+	}
+
+	results := make([]ImportRowResult, len(rows))
+	items := make([]importedTask, 0, len(rows))
+	projectAccess := map[uuid.UUID]bool{}
+	seen := map[string]int{}
+	invalid := 0
+
+	for i, row := range rows {
+		result := &results[i]
+		result.Row = i + 1
+
+		task, fieldErrors := validateImportRow(row, userUUID)
+		if task.ExternalID != nil {
+			result.ExternalID = *task.ExternalID
+			if first, dup := seen[*task.ExternalID]; dup {
+				fieldErrors["external_id"] = fmt.Sprintf("duplicates row %d", first)
+			} else {
+				seen[*task.ExternalID] = result.Row
+			}
+		}
+
+		if task.ProjectID != nil && fieldErrors["project_id"] == "" {
+			allowed, cached := projectAccess[*task.ProjectID]
+			if !cached {
+				allowed, err = canAddTasksToProject(h.DB, *task.ProjectID, userUUID, role)
+				if err != nil {
+					respondError(c, http.StatusInternalServerError, "Failed to check project access")
+					return
+				}
+				projectAccess[*task.ProjectID] = allowed
+			}
+			if !allowed {
+				fieldErrors["project_id"] = "no permission to add tasks to this project"
+			}
+		}
+
+		item := importedTask{result: result, task: task}
+		if task.ExternalID != nil {
+			item.existing = existing[*task.ExternalID]
+		}
+		if item.existing != nil && onDuplicate == importActionUpdate {
+			item.task = mergeImportRow(item.existing, &task, row)
+		}
+		switch {
+		case item.existing == nil:
+			result.Action = importActionCreate
+		case onDuplicate == importActionUpdate && item.existing.DeletedAt.Valid:
+			fieldErrors["external_id"] = "matches a task in the trash"
+		case onDuplicate == importActionUpdate && !editable[item.existing.ID]:
+			fieldErrors["external_id"] = "no permission to edit the matching task"
+		case onDuplicate == importActionUpdate && !models.CanTransition(item.existing.Status, item.task.Status) && role != "admin":
+			fieldErrors["status"] = statusTransitionMessage(item.existing.Status, item.task.Status)
+		case onDuplicate == importActionUpdate:
+			if item.task.Status != item.existing.Status {
+				if err := checkStatusPreconditions(h.DB, item.existing, item.task.Status, false); err != nil {
+					var opErr *bulkError
+					if !errors.As(err, &opErr) {
+						respondError(c, http.StatusInternalServerError, "Failed to check status change")
+						return
+					}
+					fieldErrors["status"] = opErr.Message
+					break
+				}
+			}
+			result.Action = importActionUpdate
+			result.TaskID = &item.existing.ID
+			result.Changes = diffTasks(item.existing, &item.task)
+		default:
+			result.Action = importActionSkip
+			result.TaskID = &item.existing.ID
+		}
+
+		if len(fieldErrors) > 0 {
+			result.Action = ""
+			result.TaskID = nil
+			result.Changes = nil
+			result.Errors = fieldErrors
+			invalid++
+			continue
+		}
+		items = append(items, item)
+	}
+
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Action]++
+	}
+	meta := gin.H{
+		"dry_run": dryRun,
+		"total":   len(rows),
+		"invalid": invalid,
+		"created": counts[importActionCreate],
+		"updated": counts[importActionUpdate],
+		"skipped": counts[importActionSkip],
+	}
+	if invalid > 0 && !dryRun {
+		respondErrorWithDetails(c, http.StatusBadRequest,
+			fmt.Sprintf("%d row(s) failed validation; nothing was imported", invalid),
+			gin.H{"rows": results})
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, SuccessResponse{Success: true, Data: results, Meta: meta})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			switch item.result.Action {
+			case importActionCreate:
+				task := item.task
+				if err := tx.Create(&task).Error; err != nil {
+					return err
+				}
+				if err := recordTaskEvent(tx, task.ID, userUUID, models.TaskEventCreated, diffTasks(nil, &task)); err != nil {
+					return err
+				}
+				item.result.TaskID = &task.ID
+
+			case importActionUpdate:
+				current := *item.existing
+				updated := item.task
+
+				changes := diffTasks(&current, &updated)
+				if len(changes) == 0 {
+					continue
+				}
+				allowed, err := canEditImportedTask(tx, current.ID, userUUID, role)
+				if err != nil {
+					return err
+				}
+				if !allowed {
+					return &importRowError{Row: item.result.Row, Field: "external_id", Message: "no permission to edit the matching task"}
+				}
+				// Проверки те же, что у PATCH и пакетных операций; повторяются в транзакции,
+				// потому что подзадачи и блокеры могли измениться после проверки файла
+				if updated.Status != current.Status {
+					if err := checkStatusPreconditions(tx, &current, updated.Status, false); err != nil {
+						var opErr *bulkError
+						if errors.As(err, &opErr) {
+							return &importRowError{Row: item.result.Row, Field: "status", Message: opErr.Message}
+						}
+						return err
+					}
+				}
+				if err := updateTaskVersioned(tx, &updated, changedTaskValues(&updated, changes)); err != nil {
+					return err
+				}
+				if err := recordTaskEvent(tx, updated.ID, userUUID, models.TaskEventUpdated, changes); err != nil {
+					return err
+				}
+				if updated.Status == models.StatusCompleted && current.Status != models.StatusCompleted {
+					next, err := spawnNextOccurrence(tx, &updated, userUUID)
+					if err != nil {
+						return err
+					}
+					if next != nil {
+						item.result.NextOccurrenceID = &next.ID
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		respondError(c, http.StatusConflict, "A task was modified during the import; nothing was imported")
+		return
+	}
+	var rowErr *importRowError
+	if errors.As(err, &rowErr) {
+		results[rowErr.Row-1].Action = ""
+		results[rowErr.Row-1].TaskID = nil
+		results[rowErr.Row-1].Changes = nil
+		results[rowErr.Row-1].Errors = map[string]string{rowErr.Field: rowErr.Message}
+		respondErrorWithDetails(c, http.StatusConflict,
+			fmt.Sprintf("Row %d cannot be applied; nothing was imported", rowErr.Row),
+			gin.H{"rows": results})
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to import tasks")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Success: true, Data: results, Meta: meta})
+}
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxLabelNameLength = 100
+
+type LabelHandler struct {
+	DB *gorm.DB
+}
+
+func NewLabelHandler(db *gorm.DB) *LabelHandler {
+	return &LabelHandler{DB: db}
+}
+
+type CreateLabelRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Color     string     `json:"color"`
+	ProjectID *uuid.UUID `json:"project_id"`
+}
+
+type UpdateLabelRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type AttachLabelRequest struct {
+	LabelID uuid.UUID `json:"label_id" binding:"required"`
+}
+
+func validateLabelName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "Label name cannot be empty"
+	}
+	if len([]rune(name)) > maxLabelNameLength {
+		return "", "Label name is too long"
+	}
+	return name, ""
+}
+
+const labelMemberCondition = `EXISTS (
+	SELECT 1 FROM task_schema.project_members pm
+	WHERE pm.project_id = labels.project_id AND pm.user_id = ?
+)`
+
+// visibleLabels — личные метки пользователя и метки проектов, в которых он участвует
+func visibleLabels(userID uuid.UUID, role string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if role == "admin" {
+			return db
+		}
+		return db.Where("((labels.project_id IS NULL AND labels.owner_id = ?) OR "+labelMemberCondition+")", userID, userID)
+	}
+}
+
+// canManageLabel — личной меткой управляет владелец, меткой проекта — owner/editor проекта
+func canManageLabel(db *gorm.DB, label *models.Label, userID uuid.UUID, role string) (bool, error) {
+	if role == "admin" {
+		return true, nil
+	}
+	if label.ProjectID == nil {
+		return label.OwnerID == userID, nil
+	}
+	memberRole, err := projectRole(db, *label.ProjectID, userID)
+	if err != nil {
+		return false, err
+	}
+	return memberRole.CanEdit(), nil
+}
+
+// attachLabels заполняет Labels у задач одним запросом
+func attachLabels(db *gorm.DB, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	var rows []struct {
+		models.Label
+		TaskID uuid.UUID
+	}
+	err := db.Table("task_schema.labels AS labels").
+		Select("labels.*, tl.task_id").
+		Joins("JOIN task_schema.task_labels tl ON tl.label_id = labels.id").
+		Where("tl.task_id IN ?", ids).
+		Order("lower(labels.name)").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byTask := make(map[uuid.UUID][]models.Label, len(tasks))
+	for _, r := range rows {
+		byTask[r.TaskID] = append(byTask[r.TaskID], r.Label)
+	}
+
+	for _, t := range tasks {
+		t.Labels = byTask[t.ID]
+	}
+	return nil
+}
+
+// findLabel загружает метку, видимую пользователю. При ошибке ответ уже отправлен.
+func findLabel(c *gin.Context, db *gorm.DB, labelID, userID uuid.UUID, role string) (*models.Label, bool) {
+	var label models.Label
+	err := db.Scopes(visibleLabels(userID, role)).Where("labels.id = ?", labelID).First(&label).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Label not found")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch label")
+		return nil, false
+	}
+	return &label, true
+}
+
+// GetLabels возвращает доступные метки; project_id=<id> — метки проекта, project_id=none — только личные
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Scopes(visibleLabels(userUUID, role))
+	if v := c.Query("project_id"); v != "" {
+		if v == "none" {
+			db = db.Where("labels.project_id IS NULL")
+		} else {
+			projectID, err := uuid.Parse(v)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid project_id")
+				return
+			}
+			db = db.Where("labels.project_id = ?", projectID)
+		}
+	}
+
+	labels := []models.Label{}
+	if err := db.Order("lower(labels.name)").Find(&labels).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch labels")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    labels,
+	})
+}
+
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	name, msg := validateLabelName(req.Name)
+	if msg != "" {
+		respondError(c, http.StatusBadRequest, msg)
+		return
+	}
+	if req.Color != "" && !models.IsValidLabelColor(req.Color) {
+		respondError(c, http.StatusBadRequest, "Invalid color value (expected #RRGGBB)")
+		return
+	}
+
+	// Метки проекта создают те же, кто может создавать в нём задачи
+	if req.ProjectID != nil {
+		allowed, err := canAddTasksToProject(h.DB, *req.ProjectID, userUUID, role)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to check project access")
+			return
+		}
+		if !allowed {
+			respondError(c, http.StatusForbidden, "You cannot manage labels in this project")
+			return
+		}
+	}
+
+	label := models.Label{
+		Name:      name,
+		Color:     req.Color,
+		OwnerID:   userUUID,
+		ProjectID: req.ProjectID,
+	}
+
+	if err := h.DB.Create(&label).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, "A label with this name already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to create label")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    label,
+	})
+}
+
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	labelUUID, ok := uuidParam(c, "id", "label")
+	if !ok {
+		return
+	}
+
+	var req UpdateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	label, ok := findLabel(c, h.DB, labelUUID, userUUID, role)
+	if !ok {
+		return
+	}
+
+	allowed, err := canManageLabel(h.DB, label, userUUID, role)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check label access")
+		return
+	}
+	if !allowed {
+		respondError(c, http.StatusForbidden, "You cannot modify this label")
+		return
+	}
+
+	if req.Name != "" {
+		name, msg := validateLabelName(req.Name)
+		if msg != "" {
+			respondError(c, http.StatusBadRequest, msg)
+			return
+		}
+		label.Name = name
+	}
+	if req.Color != "" {
+		if !models.IsValidLabelColor(req.Color) {
+			respondError(c, http.StatusBadRequest, "Invalid color value (expected #RRGGBB)")
+			return
+		}
+		label.Color = req.Color
+	}
+
+	if err := h.DB.Save(label).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, "A label with this name already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to update label")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    label,
+	})
+}
+
+// DeleteLabel удаляет метку и снимает её со всех задач (ON DELETE CASCADE)
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	labelUUID, ok := uuidParam(c, "id", "label")
+	if !ok {
+		return
+	}
+
+	label, ok := findLabel(c, h.DB, labelUUID, userUUID, role)
+	if !ok {
+		return
+	}
+
+	allowed, err := canManageLabel(h.DB, label, userUUID, role)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check label access")
+		return
+	}
+	if !allowed {
+		respondError(c, http.StatusForbidden, "You cannot delete this label")
+		return
+	}
+
+	if err := h.DB.Delete(label).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete label")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Label deleted successfully"},
+	})
+}
+
+// AttachLabel вешает метку на задачу. Допустимы личные метки пользователя
+// и метки проекта, которому принадлежит задача.
+func (h *TaskHandler) AttachLabel(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req AttachLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	label, ok := findLabel(c, h.DB, req.LabelID, userUUID, role)
+	if !ok {
+		return
+	}
+
+	if label.ProjectID == nil {
+		if label.OwnerID != userUUID {
+			respondError(c, http.StatusBadRequest, "Personal labels can only be attached by their owner")
+			return
+		}
+	} else if task.ProjectID == nil || *task.ProjectID != *label.ProjectID {
+		respondError(c, http.StatusBadRequest, "Label belongs to a different project")
+		return
+	}
+
+	err := h.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.TaskLabel{
+		TaskID:  task.ID,
+		LabelID: label.ID,
+	}).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to attach label")
+		return
+	}
+
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    task,
+	})
+}
+
+func (h *TaskHandler) DetachLabel(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	labelUUID, ok := uuidParam(c, "labelId", "label")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("task_id = ? AND label_id = ?", task.ID, labelUUID).Delete(&models.TaskLabel{})
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to detach label")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Label is not attached to this task")
+		return
+	}
+
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    task,
+	})
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"task-service/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// queueOutboxEvent пишет доменное событие в outbox в транзакции изменения задачи: событие
+// публикуется тогда и только тогда, когда изменение закоммичено. В событие кладётся текущее
+// состояние задачи с исполнителями; если задачи уже нет (окончательное удаление) — без него.
+func queueOutboxEvent(tx *gorm.DB, eventID uuid.UUID, eventType string, taskID, actorID uuid.UUID, changes models.FieldChanges, userIDs []uuid.UUID) error {
+	var tasks []models.Task
+	if err := tx.Unscoped().Preload("Assignees").Where("id = ?", taskID).Limit(1).Find(&tasks).Error; err != nil {
+		return err
+	}
+	var task *models.Task
+	if len(tasks) > 0 {
+		task = &tasks[0]
+	}
+
+	outbox := models.NewOutboxEvent(eventID, eventType, taskID, actorID, task, changes)
+	outbox.Payload.UserIDs = userIDs
+	return tx.Create(&outbox).Error
+}
+
+// lockTask блокирует строку задачи до конца транзакции. Нужен изменениям, которые не трогают
+// саму задачу: так события одной задачи попадают в outbox в порядке коммитов.
+func lockTask(tx *gorm.DB, taskID uuid.UUID) error {
+	var task models.Task
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", taskID).
+		First(&task).Error
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxTaskTitleLength = 255
+
+// Поля задачи, которые нельзя менять через PATCH
+var readOnlyTaskFields = map[string]bool{
+	"id": true, "created_by": true, "created_at": true, "updated_at": true, "deleted_at": true,
+	"version": true, "parent_id": true, "position": true, "series_id": true, "occurrence": true, "external_id": true,
+	"assignees": true, "labels": true, "progress": true,
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// applyTaskMergePatch применяет JSON Merge Patch (RFC 7396) к задаче: отсутствующее поле
+// не меняется, null очищает поле. Возвращает ошибки по полям.
+func applyTaskMergePatch(patch map[string]json.RawMessage, task *models.Task) map[string]string {
+	fieldErrors := map[string]string{}
+
+	for field, raw := range patch {
+		if readOnlyTaskFields[field] {
+			fieldErrors[field] = "field is read-only"
+			continue
+		}
+
+		null := isJSONNull(raw)
+		switch field {
+		case "title":
+			var title string
+			if null || json.Unmarshal(raw, &title) != nil {
+				fieldErrors[field] = "must be a non-empty string"
+				continue
+			}
+			title = strings.TrimSpace(title)
+			if title == "" {
+				fieldErrors[field] = "must be a non-empty string"
+				continue
+			}
+			if len([]rune(title)) > maxTaskTitleLength {
+				fieldErrors[field] = "must be at most 255 characters"
+				continue
+			}
+			task.Title = title
+
+		case "description":
+			if null {
+				task.Description = ""
+				continue
+			}
+			if json.Unmarshal(raw, &task.Description) != nil {
+				fieldErrors[field] = "must be a string or null"
+			}
+
+		case "status":
+			var status string
+			if null || json.Unmarshal(raw, &status) != nil || !models.IsValidStatus(status) {
+				fieldErrors[field] = "must be one of: pending, in_progress, completed, cancelled"
+				continue
+			}
+			task.Status = models.TaskStatus(status)
+
+		case "priority":
+			var priority string
+			if null || json.Unmarshal(raw, &priority) != nil || !models.IsValidPriority(priority) {
+				fieldErrors[field] = "must be one of: low, medium, high, urgent"
+				continue
+			}
+			task.Priority = models.TaskPriority(priority)
+
+		case "due_date":
+			if null {
+				task.DueDate = nil
+				continue
+			}
+			var due time.Time
+			if json.Unmarshal(raw, &due) != nil {
+				fieldErrors[field] = "must be an RFC3339 timestamp or null"
+				continue
+			}
+			task.DueDate = &due
+
+		case "project_id":
+			if null {
+				task.ProjectID = nil
+				continue
+			}
+			var projectID uuid.UUID
+			if json.Unmarshal(raw, &projectID) != nil {
+				fieldErrors[field] = "must be a project UUID or null"
+				continue
+			}
+			task.ProjectID = &projectID
+
+		default:
+			fieldErrors[field] = "unknown field"
+		}
+	}
+
+	return fieldErrors
+}
+
+// changedTaskValues возвращает значения изменённых полей задачи для UPDATE
+func changedTaskValues(task *models.Task, changes models.FieldChanges) map[string]interface{} {
+	values := make(map[string]interface{}, len(changes))
+	for field := range changes {
+		switch field {
+		case "title":
+			values[field] = task.Title
+		case "description":
+			values[field] = task.Description
+		case "status":
+			values[field] = task.Status
+		case "priority":
+			values[field] = task.Priority
+		case "due_date":
+			values[field] = task.DueDate
+		case "project_id":
+			values[field] = task.ProjectID
+		}
+	}
+	return values
+}
+
+// PatchTask частично обновляет задачу по правилам JSON Merge Patch.
+// ?force=true действует так же, как поле force в PATCH /tasks/:id/status.
+// ?scope=series переносит изменения на всю серию повторяющейся задачи.
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	scope := c.DefaultQuery("scope", "occurrence")
+	if scope != "occurrence" && scope != "series" {
+		respondError(c, http.StatusBadRequest, "scope must be one of: occurrence, series")
+		return
+	}
+
+	if ct := c.ContentType(); ct != "application/merge-patch+json" && ct != "application/json" {
+		respondError(c, http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		respondError(c, http.StatusBadRequest, "Request body must be a JSON object")
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+	if !h.checkIfMatch(c, task) {
+		return
+	}
+
+	if scope == "series" && task.SeriesID == nil {
+		respondError(c, http.StatusBadRequest, "Task is not recurring")
+		return
+	}
+
+	before := *task
+	fieldErrors := applyTaskMergePatch(patch, task)
+	if scope == "series" {
+		for field := range patch {
+			if _, failed := fieldErrors[field]; !failed && !seriesTemplateFields[field] {
+				fieldErrors[field] = "cannot be changed for the whole series"
+			}
+		}
+	}
+	if len(fieldErrors) > 0 {
+		respondErrorWithDetails(c, http.StatusBadRequest, "Validation failed", gin.H{"fields": fieldErrors})
+		return
+	}
+
+	force := c.Query("force") == "true"
+	if task.Status != before.Status {
+		if !checkStatusTransition(c, before.Status, task.Status, role, force) {
+			return
+		}
+		if task.Status == models.StatusCompleted && !h.checkSubtasksFinished(c, task) {
+			return
+		}
+		if requiresFinishedBlockers(before.Status, task.Status) && !force && !h.checkBlockersFinished(c, task) {
+			return
+		}
+	}
+
+	// Перенос задачи в проект требует прав owner/editor в целевом проекте
+	if task.ProjectID != nil && (before.ProjectID == nil || *before.ProjectID != *task.ProjectID) {
+		allowed, err := canAddTasksToProject(h.DB, *task.ProjectID, userUUID, role)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to check project access")
+			return
+		}
+		if !allowed {
+			respondErrorWithDetails(c, http.StatusForbidden, "No permission to add tasks to this project",
+				gin.H{"fields": map[string]string{"project_id": "no permission to add tasks to this project"}})
+			return
+		}
+	}
+
+	changes := diffTasks(&before, task)
+	var nextOccurrence *models.Task
+	if len(changes) > 0 {
+		values := changedTaskValues(task, changes)
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			if err := updateTaskVersioned(tx, task, values); err != nil {
+				return err
+			}
+			if err := recordTaskEvent(tx, task.ID, userUUID, models.TaskEventUpdated, changes); err != nil {
+				return err
+			}
+			if scope == "series" {
+				return applyToSeries(tx, task, changes, userUUID)
+			}
+			if task.Status == models.StatusCompleted && before.Status != models.StatusCompleted {
+				var err error
+				nextOccurrence, err = spawnNextOccurrence(tx, task, userUUID)
+				return err
+			}
+			return nil
+		})
+		if errors.Is(err, errVersionConflict) {
+			h.respondVersionConflict(c, task.ID)
+			return
+		}
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to update task")
+			return
+		}
+	}
+
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	response := SuccessResponse{
+		Success: true,
+		Data:    task,
+	}
+	if nextOccurrence != nil {
+		response.Meta = gin.H{"next_occurrence": nextOccurrence}
+	}
+
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"task-service/clients"
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProjectHandler struct {
+	DB   *gorm.DB
+	Auth *clients.AuthClient
+}
+
+func NewProjectHandler(db *gorm.DB, authClient *clients.AuthClient) *ProjectHandler {
+	return &ProjectHandler{DB: db, Auth: authClient}
+}
+
+type CreateProjectRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name" binding:"max=255"`
+	Description string `json:"description"`
+}
+
+type AddProjectMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"required"`
+}
+
+// loadProject находит проект, если пользователь его участник (или администратор).
+// При requireOwner изменять проект может только owner. При ошибке ответ уже отправлен.
+func (h *ProjectHandler) loadProject(c *gin.Context, userID uuid.UUID, role string, requireOwner bool) (*models.Project, bool) {
+	projectID, ok := uuidParam(c, "id", "project")
+	if !ok {
+		return nil, false
+	}
+
+	var project models.Project
+	if err := h.DB.Where("id = ?", projectID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Project not found")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch project")
+		return nil, false
+	}
+
+	if role == "admin" {
+		return &project, true
+	}
+
+	memberRole, err := projectRole(h.DB, project.ID, userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check project access")
+		return nil, false
+	}
+	// Не участникам не раскрываем существование проекта
+	if memberRole == "" {
+		respondError(c, http.StatusNotFound, "Project not found")
+		return nil, false
+	}
+	if requireOwner && memberRole != models.ProjectRoleOwner {
+		respondError(c, http.StatusForbidden, "Only project owners can perform this action")
+		return nil, false
+	}
+
+	return &project, true
+}
+
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	db := h.DB.Model(&models.Project{})
+	if role != "admin" {
+		db = db.Where("EXISTS (SELECT 1 FROM task_schema.project_members pm WHERE pm.project_id = projects.id AND pm.user_id = ?)", userUUID)
+	}
+
+	var projects []models.Project
+	if err := db.Order("created_at DESC").Find(&projects).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch projects")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    projects,
+	})
+}
+
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	project := models.Project{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   userUUID,
+	}
+
+	// Создатель проекта становится его владельцем
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ProjectMember{
+			ProjectID: project.ID,
+			UserID:    userUUID,
+			Role:      models.ProjectRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create project")
+		return
+	}
+
+	if err := h.DB.Preload("Members").Where("id = ?", project.ID).First(&project).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch project")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    project,
+	})
+}
+
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	project, ok := h.loadProject(c, userUUID, role, false)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Model(project).Association("Members").Find(&project.Members); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch project")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    project,
+	})
+}
+
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	project, ok := h.loadProject(c, userUUID, role, true)
+	if !ok {
+		return
+	}
+
+	if req.Name != "" {
+		project.Name = req.Name
+	}
+	if req.Description != "" {
+		project.Description = req.Description
+	}
+
+	if err := h.DB.Save(project).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to update project")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    project,
+	})
+}
+
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	project, ok := h.loadProject(c, userUUID, role, true)
+	if !ok {
+		return
+	}
+
+	// Задачи проекта не удаляются: project_id обнуляется (ON DELETE SET NULL)
+	if err := h.DB.Delete(project).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete project")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Project deleted successfully"},
+	})
+}
+
+func (h *ProjectHandler) GetProjectMembers(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	project, ok := h.loadProject(c, userUUID, role, false)
+	if !ok {
+		return
+	}
+
+	var members []models.ProjectMember
+	if err := h.DB.Where("project_id = ?", project.ID).Order("added_at").Find(&members).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch project members")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    members,
+	})
+}
+
+// AddProjectMember добавляет участника или меняет роль существующего
+func (h *ProjectHandler) AddProjectMember(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req AddProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+	if !models.IsValidProjectRole(req.Role) {
+		respondError(c, http.StatusBadRequest, "Invalid role value")
+		return
+	}
+
+	project, ok := h.loadProject(c, userUUID, role, true)
+	if !ok {
+		return
+	}
+
+	users, err := h.Auth.LookupUsers(c.Request.Context(), []uuid.UUID{req.UserID})
+	if err != nil {
+		respondError(c, http.StatusBadGateway, "Failed to verify user")
+		return
+	}
+	if _, exists := users[req.UserID]; !exists {
+		respondError(c, http.StatusBadRequest, "Unknown user ID: "+req.UserID.String())
+		return
+	}
+
+	member := models.ProjectMember{
+		ProjectID: project.ID,
+		UserID:    req.UserID,
+		Role:      models.ProjectRole(req.Role),
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if member.Role != models.ProjectRoleOwner {
+			if err := ensureAnotherOwner(tx, project.ID, req.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).Create(&member).Error
+	})
+	if errors.Is(err, errLastProjectOwner) {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to add project member")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    member,
+	})
+}
+
+// RemoveProjectMember удаляет участника; участник может покинуть проект сам
+func (h *ProjectHandler) RemoveProjectMember(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	memberUUID, ok := uuidParam(c, "userId", "user")
+	if !ok {
+		return
+	}
+
+	project, ok := h.loadProject(c, userUUID, role, memberUUID != userUUID)
+	if !ok {
+		return
+	}
+
+	var removed int64
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ensureAnotherOwner(tx, project.ID, memberUUID); err != nil {
+			return err
+		}
+		result := tx.Where("project_id = ? AND user_id = ?", project.ID, memberUUID).Delete(&models.ProjectMember{})
+		removed = result.RowsAffected
+		return result.Error
+	})
+	if errors.Is(err, errLastProjectOwner) {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to remove project member")
+		return
+	}
+	if removed == 0 {
+		respondError(c, http.StatusNotFound, "User is not a member of this project")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Member removed successfully"},
+	})
+}
+
+var errLastProjectOwner = errors.New("project must have at least one owner")
+
+// ensureAnotherOwner не даёт лишить проект последнего владельца. Строки всех владельцев
+// блокируются до конца транзакции, поэтому два параллельных запроса, снимающих разных
+// владельцев, не могут оба увидеть друг друга оставшимся владельцем.
+func ensureAnotherOwner(tx *gorm.DB, projectID, userID uuid.UUID) error {
+	var owners []models.ProjectMember
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("project_id = ? AND role = ?", projectID, models.ProjectRoleOwner).
+		Order("user_id").
+		Find(&owners).Error
+	if err != nil {
+		return err
+	}
+
+	isOwner := false
+	for _, owner := range owners {
+		if owner.UserID == userID {
+			isOwner = true
+			break
+		}
+	}
+	if isOwner && len(owners) == 1 {
+		return errLastProjectOwner
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"task-service/models"
+	"task-service/recurrence"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const upcomingOccurrencesPreview = 5
+
+type SetRecurrenceRequest struct {
+	RRule string `json:"rrule" binding:"required"`
+}
+
+type TaskRecurrence struct {
+	Series   models.TaskSeries `json:"series"`
+	Upcoming []time.Time       `json:"upcoming"`
+}
+
+// Поля, которые можно менять сразу для всей серии (scope=series)
+var seriesTemplateFields = map[string]bool{
+	"title": true, "description": true, "priority": true, "project_id": true,
+}
+
+// startSeries создаёт серию с шаблоном из задачи и делает задачу её первым повторением
+func startSeries(tx *gorm.DB, task *models.Task, rule *recurrence.Rule) (*models.TaskSeries, error) {
+	series := models.TaskSeries{
+		RRule:       rule.String(),
+		StartsAt:    *task.DueDate,
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		ProjectID:   task.ProjectID,
+		CreatedBy:   task.CreatedBy,
+	}
+	if err := tx.Create(&series).Error; err != nil {
+		return nil, err
+	}
+
+	task.SeriesID = &series.ID
+	task.Occurrence = 1
+	err := tx.Model(&models.Task{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+		"series_id":  task.SeriesID,
+		"occurrence": task.Occurrence,
+	}).Error
+	return &series, err
+}
+
+// spawnNextOccurrence создаёт следующее повторение после завершения задачи из серии.
+// Возвращает nil, если задача не повторяется, серия остановлена или закончилась,
+// либо следующее повторение уже создано.
+func spawnNextOccurrence(tx *gorm.DB, task *models.Task, actorID uuid.UUID) (*models.Task, error) {
+	if task.SeriesID == nil || task.DueDate == nil {
+		return nil, nil
+	}
+
+	var series models.TaskSeries
+	err := tx.Where("id = ? AND stopped_at IS NULL", *task.SeriesID).First(&series).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := recurrence.Parse(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+	due, ok := rule.Next(series.StartsAt, *task.DueDate, task.Occurrence)
+	if !ok {
+		return nil, nil
+	}
+
+	// Повторно завершённая (переоткрытая) задача не создаёт второе такое же повторение
+	var exists int64
+	if err := tx.Unscoped().Model(&models.Task{}).
+		Where("series_id = ? AND occurrence = ?", series.ID, task.Occurrence+1).
+		Count(&exists).Error; err != nil {
+		return nil, err
+	}
+	if exists > 0 {
+		return nil, nil
+	}
+
+	next := models.Task{
+		Title:       series.Title,
+		Description: series.Description,
+		Status:      models.StatusPending,
+		Priority:    series.Priority,
+		DueDate:     &due,
+		ProjectID:   series.ProjectID,
+		ParentID:    task.ParentID,
+		SeriesID:    &series.ID,
+		Occurrence:  task.Occurrence + 1,
+		CreatedBy:   series.CreatedBy,
+	}
+	if err := tx.Create(&next).Error; err != nil {
+		return nil, err
+	}
+
+	// Исполнители и метки переходят на следующее повторение
+	if err := tx.Exec(`
+		INSERT INTO task_schema.task_assignees (task_id, user_id, assigned_by)
+		SELECT ?, user_id, assigned_by FROM task_schema.task_assignees WHERE task_id = ?`,
+		next.ID, task.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec(`
+		INSERT INTO task_schema.task_labels (task_id, label_id)
+		SELECT ?, label_id FROM task_schema.task_labels WHERE task_id = ?`,
+		next.ID, task.ID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := recordTaskEvent(tx, next.ID, actorID, models.TaskEventCreated, diffTasks(nil, &next)); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// seriesTemplateValues возвращает изменённые шаблонные поля задачи для UPDATE
+func seriesTemplateValues(task *models.Task, changes models.FieldChanges) map[string]interface{} {
+	values := map[string]interface{}{}
+	for field := range changes {
+		switch field {
+		case "title":
+			values[field] = task.Title
+		case "description":
+			values[field] = task.Description
+		case "priority":
+			values[field] = task.Priority
+		case "project_id":
+			values[field] = task.ProjectID
+		}
+	}
+	return values
+}
+
+// applyToSeries переносит изменения шаблонных полей на серию и её незавершённые повторения
+func applyToSeries(tx *gorm.DB, task *models.Task, changes models.FieldChanges, actorID uuid.UUID) error {
+	values := seriesTemplateValues(task, changes)
+	if len(values) == 0 {
+		return nil
+	}
+
+	if err := tx.Model(&models.TaskSeries{}).Where("id = ?", *task.SeriesID).Updates(values).Error; err != nil {
+		return err
+	}
+
+	var open []models.Task
+	err := tx.Where("series_id = ? AND id <> ? AND status IN ?", *task.SeriesID, task.ID,
+		[]models.TaskStatus{models.StatusPending, models.StatusInProgress}).
+		Find(&open).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range open {
+		occurrence := &open[i]
+		before := *occurrence
+		occurrence.Title = task.Title
+		occurrence.Description = task.Description
+		occurrence.Priority = task.Priority
+		occurrence.ProjectID = task.ProjectID
+
+		// Переносятся только поля, изменённые в запросе
+		occurrenceChanges := models.FieldChanges{}
+		for field, change := range diffTasks(&before, occurrence) {
+			if _, ok := values[field]; ok {
+				occurrenceChanges[field] = change
+			}
+		}
+		*occurrence = before
+		if len(occurrenceChanges) == 0 {
+			continue
+		}
+
+		occurrenceValues := seriesTemplateValues(task, occurrenceChanges)
+		if err := updateTaskVersioned(tx, occurrence, occurrenceValues); err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, occurrence.ID, actorID, models.TaskEventUpdated, occurrenceChanges); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *TaskHandler) GetRecurrence(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+	if task.SeriesID == nil {
+		respondError(c, http.StatusNotFound, "Task is not recurring")
+		return
+	}
+
+	var series models.TaskSeries
+	if err := h.DB.Where("id = ?", *task.SeriesID).First(&series).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch recurrence")
+		return
+	}
+
+	result := TaskRecurrence{Series: series, Upcoming: []time.Time{}}
+	if rule, err := recurrence.Parse(series.RRule); err == nil && series.StoppedAt == nil && task.DueDate != nil {
+		result.Upcoming = rule.Upcoming(series.StartsAt, *task.DueDate, task.Occurrence, upcomingOccurrencesPreview)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// SetRecurrence делает задачу повторяющейся. Если задача уже входит в серию, старая серия
+// останавливается и с этой задачи начинается новая («это и последующие повторения»).
+func (h *TaskHandler) SetRecurrence(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req SetRecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	rule, err := recurrence.Parse(req.RRule)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid recurrence rule: "+err.Error())
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+	if task.DueDate == nil {
+		respondError(c, http.StatusBadRequest, "A recurring task must have a due_date")
+		return
+	}
+
+	var series *models.TaskSeries
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if task.SeriesID != nil {
+			if err := tx.Model(&models.TaskSeries{}).
+				Where("id = ? AND stopped_at IS NULL", *task.SeriesID).
+				Update("stopped_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		series, err = startSeries(tx, task, rule)
+		return err
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to set recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: TaskRecurrence{
+			Series:   *series,
+			Upcoming: rule.Upcoming(series.StartsAt, *task.DueDate, task.Occurrence, upcomingOccurrencesPreview),
+		},
+	})
+}
+
+// DeleteRecurrence останавливает серию: уже созданные повторения остаются, новые не создаются
+func (h *TaskHandler) DeleteRecurrence(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+	if task.SeriesID == nil {
+		respondError(c, http.StatusNotFound, "Task is not recurring")
+		return
+	}
+
+	err := h.DB.Model(&models.TaskSeries{}).
+		Where("id = ? AND stopped_at IS NULL", *task.SeriesID).
+		Update("stopped_at", time.Now()).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to stop recurrence")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Recurrence stopped"},
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchQueryLen  = 200
+
+	// ts_headline отмечает совпадения управляющими символами, а не тегами: текст задачи
+	// экранируется уже после этого, и в HTML превращаются только сами отметки.
+	// Из исходного текста эти символы вырезаются, чтобы его нельзя было принять за отметку.
+	headlineStart   = "\x01"
+	headlineStop    = "\x02"
+	headlineMarkers = headlineStart + headlineStop
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop
+)
+
+var headlineReplacer = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// renderHeadline экранирует HTML в тексте ts_headline и заменяет отметки на <mark>
+func renderHeadline(s string) string {
+	return headlineReplacer.Replace(html.EscapeString(s))
+}
+
+type TaskSearchResult struct {
+	models.Task
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// buildPrefixTSQuery превращает пользовательский ввод в tsquery вида "foo:* & bar:*".
+// Спецсимволы tsquery отбрасываются, поэтому ввод не может сломать синтаксис запроса.
+func buildPrefixTSQuery(input string) string {
+	terms := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+func (h *TaskHandler) SearchTasks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		respondError(c, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+	if len(q) > maxSearchQueryLen {
+		respondError(c, http.StatusBadRequest, "Search query is too long")
+		return
+	}
+
+	tsquery := buildPrefixTSQuery(q)
+	if tsquery == "" {
+		respondError(c, http.StatusBadRequest, "Search query contains no searchable terms")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondError(c, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	db := h.DB.Table(models.Task{}.TableName()+" AS tasks").
+		Select(`tasks.*,
+			ts_rank_cd(tasks.search_vector, query) AS rank,
+			ts_headline('simple', translate(coalesce(tasks.title, ''), ?, ''), query, ?) AS title_highlight,
+			ts_headline('simple', translate(coalesce(tasks.description, ''), ?, ''), query, ?) AS snippet`,
+			headlineMarkers, headlineOptions+", HighlightAll=true",
+			headlineMarkers, headlineOptions+", MaxWords=35, MinWords=15").
+		Joins("CROSS JOIN to_tsquery('simple', ?) AS query", tsquery).
+		Where("tasks.search_vector @@ query")
+
+	// Те же правила видимости, что и в GetTasks. Запрос строится через Table, поэтому
+	// фильтр мягкого удаления GORM не добавляет: задачи из корзины отсекаются явно.
+	db = db.Scopes(visibleTasks(userUUID, role)).Where("tasks.deleted_at IS NULL")
+
+	var results []TaskSearchResult
+	if err := db.Order("rank DESC, tasks.created_at DESC").Limit(limit).Scan(&results).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to search tasks")
+		return
+	}
+	for i := range results {
+		results[i].TitleHighlight = renderHeadline(results[i].TitleHighlight)
+		results[i].Snippet = renderHeadline(results[i].Snippet)
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    results,
+	})
+}
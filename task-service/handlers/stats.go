@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultStatsWindowDays = 30
+	maxStatsWindowDays     = 365
+)
+
+// TaskStats — сводка для дашборда
+type TaskStats struct {
+	Scope       string           `json:"scope"`
+	Total       int64            `json:"total"`
+	ByStatus    map[string]int64 `json:"by_status"`
+	ByPriority  map[string]int64 `json:"by_priority"`
+	Overdue     int64            `json:"overdue"`
+	DueThisWeek int64            `json:"due_this_week"`
+	Window      TaskStatsWindow  `json:"window"`
+}
+
+// TaskStatsWindow — показатели за последние Days дней
+type TaskStatsWindow struct {
+	Days  int       `json:"days"`
+	Since time.Time `json:"since"`
+	// Задачи, созданные за период (кроме отменённых), и сколько из них уже завершено
+	Created        int64   `json:"created"`
+	Completed      int64   `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+	// Среднее время от создания до завершения у задач, завершённых за период
+	CompletedInWindow    int64    `json:"completed_in_window"`
+	AvgCompletionSeconds *float64 `json:"avg_completion_seconds"`
+}
+
+// taskCounts — строка агрегатного запроса по задачам
+type taskCounts struct {
+	Total          int64
+	Pending        int64
+	InProgress     int64
+	Completed      int64
+	Cancelled      int64
+	Low            int64
+	Medium         int64
+	High           int64
+	Urgent         int64
+	Overdue        int64
+	DueThisWeek    int64
+	Created        int64
+	CreatedDone    int64
+	CompletionRate float64
+}
+
+// GetTaskStats считает сводку по задачам. Обычный пользователь видит статистику по своим задачам
+// (автор, исполнитель, участник проекта), администратор — по всем (scope=mine сужает до своих).
+// ?days задаёт период для доли завершённых и среднего времени выполнения.
+func (h *TaskHandler) GetTaskStats(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	days := defaultStatsWindowDays
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxStatsWindowDays {
+			respondError(c, http.StatusBadRequest, "days must be between 1 and "+strconv.Itoa(maxStatsWindowDays))
+			return
+		}
+		days = n
+	}
+
+	scope := "mine"
+	if role == "admin" {
+		scope = "all"
+	}
+	switch v := c.Query("scope"); v {
+	case "":
+	case "mine":
+		scope = v
+	case "all":
+		if role != "admin" {
+			respondError(c, http.StatusForbidden, "Only admins can see system-wide statistics")
+			return
+		}
+		scope = v
+	default:
+		respondError(c, http.StatusBadRequest, "scope must be one of: mine, all")
+		return
+	}
+
+	// В режиме mine администратор получает те же задачи, что и обычный участник
+	tasks := func() *gorm.DB {
+		db := h.DB.Model(&models.Task{})
+		if scope == "mine" {
+			db = db.Scopes(visibleTasks(userUUID, ""))
+		}
+		return db
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+	open := []models.TaskStatus{models.StatusPending, models.StatusInProgress}
+
+	var counts taskCounts
+	err := tasks().Select(`
+		COUNT(*) AS total,
+		COUNT(*) FILTER (WHERE tasks.status = 'pending') AS pending,
+		COUNT(*) FILTER (WHERE tasks.status = 'in_progress') AS in_progress,
+		COUNT(*) FILTER (WHERE tasks.status = 'completed') AS completed,
+		COUNT(*) FILTER (WHERE tasks.status = 'cancelled') AS cancelled,
+		COUNT(*) FILTER (WHERE tasks.priority = 'low') AS low,
+		COUNT(*) FILTER (WHERE tasks.priority = 'medium') AS medium,
+		COUNT(*) FILTER (WHERE tasks.priority = 'high') AS high,
+		COUNT(*) FILTER (WHERE tasks.priority = 'urgent') AS urgent,
+		COUNT(*) FILTER (WHERE tasks.status IN ? AND tasks.due_date < ?) AS overdue,
+		COUNT(*) FILTER (WHERE tasks.status IN ?
+			AND tasks.due_date >= date_trunc('week', ?::timestamp)
+			AND tasks.due_date < date_trunc('week', ?::timestamp) + INTERVAL '7 days') AS due_this_week,
+		COUNT(*) FILTER (WHERE tasks.created_at >= ? AND tasks.status <> 'cancelled') AS created,
+		COUNT(*) FILTER (WHERE tasks.created_at >= ? AND tasks.status = 'completed') AS created_done,
+		COALESCE(100.0 * COUNT(*) FILTER (WHERE tasks.created_at >= ? AND tasks.status = 'completed')
+			/ NULLIF(COUNT(*) FILTER (WHERE tasks.created_at >= ? AND tasks.status <> 'cancelled'), 0), 0) AS completion_rate`,
+		open, now, open, now, now, since, since, since, since).
+		Scan(&counts).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to calculate task statistics")
+		return
+	}
+
+	// Момент завершения — последнее событие журнала, в котором статус стал completed.
+	// Для задач без такого события (созданных до появления журнала) берётся updated_at,
+	// он не раньше завершения, поэтому по нему же можно отсечь старые задачи до подзапроса.
+	var completion struct {
+		Completed  int64
+		AvgSeconds *float64
+	}
+	err = tasks().
+		Joins(`LEFT JOIN LATERAL (
+			SELECT MAX(e.created_at) AS completed_at FROM task_schema.task_events e
+			WHERE e.task_id = tasks.id AND e.changes->'status'->>'new' = 'completed'
+		) completion ON TRUE`).
+		Select(`COUNT(*) AS completed,
+			AVG(EXTRACT(EPOCH FROM COALESCE(completion.completed_at, tasks.updated_at) - tasks.created_at)) AS avg_seconds`).
+		Where("tasks.status = ? AND tasks.updated_at >= ?", models.StatusCompleted, since).
+		Where("COALESCE(completion.completed_at, tasks.updated_at) >= ?", since).
+		Scan(&completion).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to calculate task statistics")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: TaskStats{
+			Scope: scope,
+			Total: counts.Total,
+			ByStatus: map[string]int64{
+				string(models.StatusPending):    counts.Pending,
+				string(models.StatusInProgress): counts.InProgress,
+				string(models.StatusCompleted):  counts.Completed,
+				string(models.StatusCancelled):  counts.Cancelled,
+			},
+			ByPriority: map[string]int64{
+				string(models.PriorityLow):    counts.Low,
+				string(models.PriorityMedium): counts.Medium,
+				string(models.PriorityHigh):   counts.High,
+				string(models.PriorityUrgent): counts.Urgent,
+			},
+			Overdue:     counts.Overdue,
+			DueThisWeek: counts.DueThisWeek,
+			Window: TaskStatsWindow{
+				Days:                 days,
+				Since:                since,
+				Created:              counts.Created,
+				Completed:            counts.CreatedDone,
+				CompletionRate:       counts.CompletionRate,
+				CompletedInWindow:    completion.Completed,
+				AvgCompletionSeconds: completion.AvgSeconds,
+			},
+		},
+	})
+}
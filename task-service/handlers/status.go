@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkStatusTransition проверяет переход по таблице статусов. Администратор может
+// обойти таблицу, передав force. При ошибке ответ уже отправлен.
+func checkStatusTransition(c *gin.Context, from, to models.TaskStatus, role string, force bool) bool {
+	if models.CanTransition(from, to) || (role == "admin" && force) {
+		return true
+	}
+
+	respondErrorWithDetails(c, http.StatusConflict, statusTransitionMessage(from, to), statusTransitionDetails(from, to))
+	return false
+}
+
+func statusTransitionMessage(from, to models.TaskStatus) string {
+	return fmt.Sprintf("Cannot change status from %s to %s", from, to)
+}
+
+// statusTransitionDetails — details ответа 409 о недопустимом переходе
+func statusTransitionDetails(from, to models.TaskStatus) gin.H {
+	allowed := models.AllowedTransitions(from)
+	if allowed == nil {
+		allowed = []models.TaskStatus{}
+	}
+	return gin.H{
+		"current_status":   from,
+		"requested_status": to,
+		"allowed_statuses": allowed,
+	}
+}
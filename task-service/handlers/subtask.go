@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"task-service/models"
+	"task-service/recurrence"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errMaxTaskDepth = errors.New("maximum task depth reached")
+
+type ReorderSubtasksRequest struct {
+	TaskIDs []uuid.UUID `json:"task_ids" binding:"required"`
+}
+
+// attachProgress заполняет Progress у задач, у которых есть подзадачи, одним запросом
+func attachProgress(db *gorm.DB, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	var rows []struct {
+		ParentID  uuid.UUID
+		Total     int64
+		Completed int64
+		Open      int64
+		Cancelled int64
+	}
+	err := db.Model(&models.Task{}).
+		Select(`parent_id,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE status IN ('pending', 'in_progress')) AS open,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled`).
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byParent := make(map[uuid.UUID]*models.TaskProgress, len(rows))
+	for _, r := range rows {
+		progress := &models.TaskProgress{
+			TotalChildren:     r.Total,
+			CompletedChildren: r.Completed,
+			OpenChildren:      r.Open,
+		}
+		if counted := r.Total - r.Cancelled; counted > 0 {
+			progress.PercentComplete = float64(r.Completed) * 100 / float64(counted)
+		}
+		byParent[r.ParentID] = progress
+	}
+
+	for _, t := range tasks {
+		t.Progress = byParent[t.ID]
+	}
+	return nil
+}
+
+// taskDepth возвращает уровень задачи в иерархии (корневая задача — 1)
+func taskDepth(db *gorm.DB, taskID uuid.UUID) (int, error) {
+	var depth int
+	err := db.Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 1 AS depth FROM task_schema.tasks WHERE id = ?
+			UNION ALL
+			SELECT t.id, t.parent_id, a.depth + 1
+			FROM task_schema.tasks t
+			JOIN ancestors a ON t.id = a.parent_id
+		)
+		SELECT COALESCE(MAX(depth), 0) FROM ancestors`, taskID).Scan(&depth).Error
+	return depth, err
+}
+
+func hasOpenSubtasks(db *gorm.DB, taskID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.Task{}).
+		Where("parent_id = ? AND status IN ?", taskID, []models.TaskStatus{models.StatusPending, models.StatusInProgress}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// checkSubtasksFinished не даёт завершить задачу, пока есть подзадачи в pending или in_progress.
+// При ошибке ответ уже отправлен.
+func (h *TaskHandler) checkSubtasksFinished(c *gin.Context, task *models.Task) bool {
+	open, err := hasOpenSubtasks(h.DB, task.ID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to check subtasks")
+		return false
+	}
+	if open {
+		respondError(c, http.StatusConflict, "Task cannot be completed while it has pending or in-progress subtasks")
+		return false
+	}
+	return true
+}
+
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	parent, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	var subtasks []models.Task
+	err := h.DB.Scopes(visibleTasks(userUUID, role)).
+		Preload("Assignees").
+		Where("tasks.parent_id = ?", parent.ID).
+		Order("tasks.position, tasks.created_at").
+		Find(&subtasks).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch subtasks")
+		return
+	}
+
+	if err := enrichTaskList(h.DB, subtasks); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    subtasks,
+	})
+}
+
+func (h *TaskHandler) CreateSubtask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+	if req.Status != "" && !models.IsValidStatus(req.Status) {
+		respondError(c, http.StatusBadRequest, "Invalid status value")
+		return
+	}
+	if req.Priority != "" && !models.IsValidPriority(req.Priority) {
+		respondError(c, http.StatusBadRequest, "Invalid priority value")
+		return
+	}
+
+	var rule *recurrence.Rule
+	if req.Recurrence != "" {
+		var err error
+		rule, err = recurrence.Parse(req.Recurrence)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid recurrence rule: "+err.Error())
+			return
+		}
+		if req.DueDate == nil {
+			respondError(c, http.StatusBadRequest, "A recurring task must have a due_date")
+			return
+		}
+	}
+
+	// Добавлять подзадачи может тот, кто может изменять родительскую задачу
+	parent, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	// Подзадача всегда принадлежит проекту родителя
+	task := models.Task{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      models.TaskStatus(req.Status),
+		Priority:    models.TaskPriority(req.Priority),
+		DueDate:     req.DueDate,
+		ProjectID:   parent.ProjectID,
+		ParentID:    &parent.ID,
+		CreatedBy:   userUUID,
+	}
+
+	// Родитель блокируется до конца транзакции: параллельные создания подзадач
+	// получают разные позиции, а глубина проверяется по актуальному дереву
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, parent.ID); err != nil {
+			return err
+		}
+
+		depth, err := taskDepth(tx, parent.ID)
+		if err != nil {
+			return err
+		}
+		if depth+1 > h.Config.MaxTaskDepth {
+			return errMaxTaskDepth
+		}
+
+		err = tx.Model(&models.Task{}).
+			Select("COALESCE(MAX(position), -1) + 1").
+			Where("parent_id = ?", parent.ID).
+			Scan(&task.Position).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if rule != nil {
+			if _, err := startSeries(tx, &task, rule); err != nil {
+				return err
+			}
+		}
+		return recordTaskEvent(tx, task.ID, userUUID, models.TaskEventCreated, diffTasks(nil, &task))
+	})
+	if errors.Is(err, errMaxTaskDepth) {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("Maximum task depth of %d reached", h.Config.MaxTaskDepth))
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, "Task not found")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create subtask")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    task,
+	})
+}
+
+// ReorderSubtasks задаёт новый порядок подзадач; в запросе должны быть перечислены все подзадачи
+func (h *TaskHandler) ReorderSubtasks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req ReorderSubtasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+
+	parent, ok := h.findTask(c, taskUUID, userUUID, role, accessEdit)
+	if !ok {
+		return
+	}
+
+	var childIDs []uuid.UUID
+	if err := h.DB.Model(&models.Task{}).Where("parent_id = ?", parent.ID).Pluck("id", &childIDs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch subtasks")
+		return
+	}
+
+	children := make(map[uuid.UUID]bool, len(childIDs))
+	for _, id := range childIDs {
+		children[id] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(req.TaskIDs))
+	for _, id := range req.TaskIDs {
+		if !children[id] || seen[id] {
+			respondError(c, http.StatusBadRequest, "task_ids must list every subtask exactly once")
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(children) {
+		respondError(c, http.StatusBadRequest, "task_ids must list every subtask exactly once")
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range req.TaskIDs {
+			if err := tx.Model(&models.Task{}).Where("id = ?", id).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to reorder subtasks")
+		return
+	}
+
+	var subtasks []models.Task
+	if err := h.DB.Preload("Assignees").Where("parent_id = ?", parent.ID).Order("position").Find(&subtasks).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch subtasks")
+		return
+	}
+	if err := enrichTaskList(h.DB, subtasks); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    subtasks,
+	})
+}
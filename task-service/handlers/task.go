@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
+	"task-service/clients"
+	"task-service/config"
 	"task-service/models"
+	"task-service/recurrence"
+	"task-service/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TaskHandler struct {
-	DB *gorm.DB
+	DB      *gorm.DB
+	Auth    *clients.AuthClient
+	Config  *config.Config
+	Storage storage.Storage
 }
 
-func NewTaskHandler(db *gorm.DB) *TaskHandler {
-	return &TaskHandler{DB: db}
+func NewTaskHandler(db *gorm.DB, authClient *clients.AuthClient, cfg *config.Config, store storage.Storage) *TaskHandler {
+	return &TaskHandler{DB: db, Auth: authClient, Config: cfg, Storage: store}
 }
 
 type CreateTaskRequest struct {
@@ -25,6 +34,9 @@ type CreateTaskRequest struct {
 	Status      string     `json:"status"`
 	Priority    string     `json:"priority"`
 	DueDate     *time.Time `json:"due_date"`
+	ProjectID   *uuid.UUID `json:"project_id"`
+	// Recurrence — правило повторения (подмножество RRULE), требует due_date
+	Recurrence string `json:"recurrence"`
 }
 
 type UpdateTaskRequest struct {
@@ -33,21 +45,29 @@ type UpdateTaskRequest struct {
 	Status      string     `json:"status"`
 	Priority    string     `json:"priority"`
 	DueDate     *time.Time `json:"due_date"`
+	ProjectID   *uuid.UUID `json:"project_id"`
+	// Force — см. UpdateTaskStatusRequest
+	Force bool `json:"force"`
 }
 
 type UpdateTaskStatusRequest struct {
 	Status string `json:"status" binding:"required"`
+	// Force разрешает начать или завершить задачу, у которой есть незавершённые блокеры.
+	// Администратору он также позволяет обойти таблицу переходов статусов.
+	Force bool `json:"force"`
 }
 
 type SuccessResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data"`
+	Meta    interface{} `json:"meta,omitempty"`
 }
 
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    int    `json:"code"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error"`
+	Code    int         `json:"code"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 func (h *TaskHandler) HealthCheck(c *gin.Context) {
@@ -87,7 +107,7 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		return
 	}
 
-	role, _ := c.Get("role") // Получаем роль
+	role := c.GetString("role")
 
 	userIDStr, ok := userID.(string)
 	if !ok {
@@ -109,34 +129,58 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		return
 	}
 
+	// view=<id> применяет сохранённое представление; без параметров — представление по умолчанию
+	query, view, ok := taskListQueryWithView(c, h.DB, userUUID, true)
+	if !ok {
+		return
+	}
+
+	// Администратор видит все задачи, остальные — свои, назначенные на них и задачи своих проектов
+	base := query.filters(h.DB.Model(&models.Task{}).Scopes(visibleTasks(userUUID, role)))
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to count tasks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
 	var tasks []models.Task
+	if err := query.page(base.Session(&gorm.Session{})).Preload("Assignees").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch tasks",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
 
-	// Если пользователь администратор, показываем все задачи, иначе только свои
-	if role == "admin" {
-		result := h.DB.Find(&tasks)
-		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Success: false,
-				Error:   "Failed to fetch tasks",
-				Code:    http.StatusInternalServerError,
-			})
-			return
-		}
-	} else {
-		result := h.DB.Where("created_by = ?", userUUID).Find(&tasks)
-		if result.Error != nil {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{
-				Success: false,
-				Error:   "Failed to fetch tasks",
-				Code:    http.StatusInternalServerError,
-			})
-			return
-		}
+	if err := enrichTaskList(h.DB, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to load task details",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	meta := ListMeta{TotalCount: total, Limit: query.Limit}
+	if view != nil {
+		meta.ViewID = &view.ID
+	}
+	if len(tasks) > query.Limit {
+		tasks = tasks[:query.Limit]
+		next := query.cursorFor(tasks[len(tasks)-1])
+		meta.NextCursor = &next
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data:    tasks,
+		Meta:    meta,
 	})
 }
 
@@ -151,7 +195,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
-	role, _ := c.Get("role")
+	role := c.GetString("role")
 
 	userIDStr, ok := userID.(string)
 	if !ok {
@@ -185,13 +229,10 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 	}
 
 	var task models.Task
-	var result *gorm.DB
-
-	if role == "admin" {
-		result = h.DB.Where("id = ?", taskUUID).First(&task)
-	} else {
-		result = h.DB.Where("id = ? AND created_by = ?", taskUUID, userUUID).First(&task)
-	}
+	result := h.DB.Scopes(visibleTasks(userUUID, role)).
+		Preload("Assignees").
+		Where("tasks.id = ?", taskUUID).
+		First(&task)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
@@ -210,6 +251,16 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		return
 	}
 
+	if err := enrichTasks(h.DB, &task); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to load task details",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setTaskETag(c, &task)
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data:    task,
@@ -293,17 +344,71 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		}
 	}
 
+	// Создавать задачи в проекте могут только owner/editor проекта
+	if req.ProjectID != nil {
+		allowed, err := canAddTasksToProject(h.DB, *req.ProjectID, userUUID, c.GetString("role"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Success: false,
+				Error:   "Failed to check project access",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Success: false,
+				Error:   "No permission to add tasks to this project",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+	}
+
+	// Валидация правила повторения
+	var rule *recurrence.Rule
+	if req.Recurrence != "" {
+		rule, err = recurrence.Parse(req.Recurrence)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error:   "Invalid recurrence rule: " + err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+		if req.DueDate == nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Success: false,
+				Error:   "A recurring task must have a due_date",
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
 	task := models.Task{
 		Title:       req.Title,
 		Description: req.Description,
 		Status:      models.TaskStatus(req.Status),
 		Priority:    models.TaskPriority(req.Priority),
 		DueDate:     req.DueDate,
+		ProjectID:   req.ProjectID,
 		CreatedBy:   userUUID,
 	}
 
-	result := h.DB.Create(&task)
-	if result.Error != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if rule != nil {
+			if _, err := startSeries(tx, &task, rule); err != nil {
+				return err
+			}
+		}
+		return recordTaskEvent(tx, task.ID, userUUID, models.TaskEventCreated, diffTasks(nil, &task))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Success: false,
 			Error:   "Failed to create task",
@@ -312,6 +417,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
+	setTaskETag(c, &task)
 	c.JSON(http.StatusCreated, SuccessResponse{
 		Success: true,
 		Data:    task,
@@ -329,7 +435,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	role, _ := c.Get("role")
+	role := c.GetString("role")
 
 	userIDStr, ok := userID.(string)
 	if !ok {
@@ -374,13 +480,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 
 	// Находим задачу
 	var task models.Task
-	var result *gorm.DB
-
-	if role == "admin" {
-		result = h.DB.Where("id = ?", taskUUID).First(&task)
-	} else {
-		result = h.DB.Where("id = ? AND created_by = ?", taskUUID, userUUID).First(&task)
-	}
+	result := h.DB.Scopes(editableTasks(userUUID, role)).Where("tasks.id = ?", taskUUID).First(&task)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
@@ -399,6 +499,12 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	if !h.checkIfMatch(c, &task) {
+		return
+	}
+
+	before := task
+
 	// Обновляем поля
 	if req.Title != "" {
 		task.Title = req.Title
@@ -422,7 +528,18 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 			})
 			return
 		}
-		task.Status = models.TaskStatus(req.Status)
+
+		newStatus := models.TaskStatus(req.Status)
+		if !checkStatusTransition(c, task.Status, newStatus, role, req.Force) {
+			return
+		}
+		if newStatus == models.StatusCompleted && !h.checkSubtasksFinished(c, &task) {
+			return
+		}
+		if requiresFinishedBlockers(task.Status, newStatus) && !req.Force && !h.checkBlockersFinished(c, &task) {
+			return
+		}
+		task.Status = newStatus
 	}
 	if req.Priority != "" {
 		// Валидация приоритета
@@ -445,9 +562,56 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	if req.DueDate != nil {
 		task.DueDate = req.DueDate
 	}
+	if req.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *req.ProjectID) {
+		// Перенос задачи в проект требует прав owner/editor в целевом проекте
+		allowed, err := canAddTasksToProject(h.DB, *req.ProjectID, userUUID, role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Success: false,
+				Error:   "Failed to check project access",
+				Code:    http.StatusInternalServerError,
+			})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Success: false,
+				Error:   "No permission to add tasks to this project",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+		task.ProjectID = req.ProjectID
+	}
 
-	result = h.DB.Save(&task)
-	if result.Error != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		err := updateTaskVersioned(tx, &task, map[string]interface{}{
+			"title":       task.Title,
+			"description": task.Description,
+			"status":      task.Status,
+			"priority":    task.Priority,
+			"due_date":    task.DueDate,
+			"project_id":  task.ProjectID,
+		})
+		if err != nil {
+			return err
+		}
+		if changes := diffTasks(&before, &task); len(changes) > 0 {
+			if err := recordTaskEvent(tx, task.ID, userUUID, models.TaskEventUpdated, changes); err != nil {
+				return err
+			}
+		}
+		if task.Status == models.StatusCompleted && before.Status != models.StatusCompleted {
+			_, err := spawnNextOccurrence(tx, &task, userUUID)
+			return err
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		h.respondVersionConflict(c, task.ID)
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Success: false,
 			Error:   "Failed to update task",
@@ -456,6 +620,25 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	// Отвечаем задачей в том же виде, что и GET: с исполнителями, метками и прогрессом
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(&task).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to fetch task",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if err := enrichTasks(h.DB, &task); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to load task details",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	setTaskETag(c, &task)
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
 		Data:    task,
@@ -473,7 +656,7 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	role, _ := c.Get("role")
+	role := c.GetString("role")
 
 	userIDStr, ok := userID.(string)
 	if !ok {
@@ -532,18 +715,51 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	var result *gorm.DB
-	if role == "admin" {
-		result = h.DB.Model(&models.Task{}).
-			Where("id = ?", taskUUID).
-			Update("status", req.Status)
-	} else {
-		result = h.DB.Model(&models.Task{}).
-			Where("id = ? AND created_by = ?", taskUUID, userUUID).
-			Update("status", req.Status)
+	// Статус могут менять автор, исполнители, owner/editor проекта и администратор
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessStatus)
+	if !ok {
+		return
+	}
+	if !h.checkIfMatch(c, task) {
+		return
 	}
 
-	if result.Error != nil {
+	newStatus := models.TaskStatus(req.Status)
+	if !checkStatusTransition(c, task.Status, newStatus, role, req.Force) {
+		return
+	}
+	if newStatus == models.StatusCompleted && !h.checkSubtasksFinished(c, task) {
+		return
+	}
+	if requiresFinishedBlockers(task.Status, newStatus) && !req.Force && !h.checkBlockersFinished(c, task) {
+		return
+	}
+
+	before := *task
+	var nextOccurrence *models.Task
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := updateTaskVersioned(tx, task, map[string]interface{}{"status": newStatus}); err != nil {
+			return err
+		}
+		task.Status = newStatus
+		if changes := diffTasks(&before, task); len(changes) > 0 {
+			if err := recordTaskEvent(tx, task.ID, userUUID, models.TaskEventStatusChanged, changes); err != nil {
+				return err
+			}
+		}
+		// Завершение повторяющейся задачи создаёт следующее повторение
+		if newStatus == models.StatusCompleted && before.Status != models.StatusCompleted {
+			var err error
+			nextOccurrence, err = spawnNextOccurrence(tx, task, userUUID)
+			return err
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionConflict) {
+		h.respondVersionConflict(c, task.ID)
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Success: false,
 			Error:   "Failed to update task status",
@@ -552,23 +768,35 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{
+	// Получаем обновленную задачу
+	// ETag выдаётся только вместе с полностью загруженной задачей
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Success: false,
-			Error:   "Task not found",
-			Code:    http.StatusNotFound,
+			Error:   "Failed to fetch task",
+			Code:    http.StatusInternalServerError,
+		})
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Success: false,
+			Error:   "Failed to load task details",
+			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
 
-	// Получаем обновленную задачу
-	var task models.Task
-	h.DB.Where("id = ?", taskUUID).First(&task)
-
-	c.JSON(http.StatusOK, SuccessResponse{
+	response := SuccessResponse{
 		Success: true,
 		Data:    task,
-	})
+	}
+	if nextOccurrence != nil {
+		response.Meta = gin.H{"next_occurrence": nextOccurrence}
+	}
+
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
@@ -582,7 +810,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	role, _ := c.Get("role")
+	role := c.GetString("role")
 
 	userIDStr, ok := userID.(string)
 	if !ok {
@@ -615,33 +843,61 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	var result *gorm.DB
-	if role == "admin" {
-		result = h.DB.Where("id = ?", taskUUID).Delete(&models.Task{})
-	} else {
-		result = h.DB.Where("id = ? AND created_by = ?", taskUUID, userUUID).Delete(&models.Task{})
+	ifMatch, ok := requireIfMatch(c)
+	if !ok {
+		return
 	}
 
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
+	// Задача вместе с подзадачами переносится в корзину; удаление и запись в журнал —
+	// в одной транзакции, строка блокируется до коммита
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(editableTasks(userUUID, role)).
+			Where("tasks.id = ?", taskUUID).
+			First(&task).Error
+		if err != nil {
+			return err
+		}
+		if !etagMatches(ifMatch, task.Version) {
+			return errVersionConflict
+		}
+
+		now := time.Now()
+		if err := updateTaskVersioned(tx, &task, map[string]interface{}{"deleted_at": now}); err != nil {
+			return err
+		}
+		if err := setSubtreeDeletedAt(tx, task.ID, nil, &now); err != nil {
+			return err
+		}
+		return recordTaskEvent(tx, task.ID, userUUID, models.TaskEventDeleted, diffTasks(&task, nil))
+	})
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
 			Success: false,
-			Error:   "Failed to delete task",
-			Code:    http.StatusInternalServerError,
+			Error:   "Task not found",
+			Code:    http.StatusNotFound,
 		})
 		return
 	}
 
-	if result.RowsAffected == 0 {
-		c.JSON(http.StatusNotFound, ErrorResponse{
+	if errors.Is(err, errVersionConflict) {
+		h.respondVersionConflict(c, taskUUID)
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Success: false,
-			Error:   "Task not found",
-			Code:    http.StatusNotFound,
+			Error:   "Failed to delete task",
+			Code:    http.StatusInternalServerError,
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, SuccessResponse{
 		Success: true,
-		Data:    gin.H{"message": "Task deleted successfully"},
+		Data:    gin.H{"message": "Task moved to trash"},
 	})
 }
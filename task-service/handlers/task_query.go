@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-service/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTaskListLimit = 50
+	maxTaskListLimit     = 200
+
+	// Формат значения курсора для колонок TIMESTAMP (без часового пояса)
+	cursorTimeLayout = "2006-01-02T15:04:05.999999"
+)
+
+// Выражения сортировки; приоритет сортируется по весу, а не по алфавиту.
+// Для каждого выражения есть индекс (выражение, id) — при изменении выражения
+// нужна миграция, пересоздающая индекс (см. 023_add_task_sort_indexes).
+var taskSortExpressions = map[string]string{
+	"created_at": "tasks.created_at",
+	"due_date":   "COALESCE(tasks.due_date, 'infinity'::timestamp)",
+	"priority":   "CASE tasks.priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 ELSE 0 END",
+}
+
+var taskSortCasts = map[string]string{
+	"created_at": "timestamp",
+	"due_date":   "timestamp",
+	"priority":   "integer",
+}
+
+type ListMeta struct {
+	NextCursor *string `json:"next_cursor"`
+	TotalCount int64   `json:"total_count"`
+	Limit      int     `json:"limit"`
+	// Применённое сохранённое представление
+	ViewID *uuid.UUID `json:"view_id,omitempty"`
+}
+
+type taskCursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+type taskListQuery struct {
+	Statuses   []string
+	Priorities []string
+	DueAfter   *time.Time
+	DueBefore  *time.Time
+	CreatedBy  *uuid.UUID
+	Assignee   *uuid.UUID
+	Watcher    *uuid.UUID
+	ProjectID  *uuid.UUID
+	NoProject  bool
+	ParentID   *uuid.UUID
+	TopLevel   bool
+	Labels     []uuid.UUID
+	LabelMode  string
+	Sort       string
+	Order      string
+	Limit      int
+	Cursor     *taskCursor
+}
+
+// parseTaskListValues разбирает параметры списка задач; userID нужен для assignee=me и watcher=me.
+// Параметры берутся из запроса, объединённого с сохранённым представлением (см. taskListQueryWithView).
+func parseTaskListValues(values url.Values, userID string) (*taskListQuery, error) {
+	q := &taskListQuery{
+		Sort:  "created_at",
+		Order: "desc",
+		Limit: defaultTaskListLimit,
+	}
+
+	if v := values.Get("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if !models.IsValidStatus(s) {
+				return nil, fmt.Errorf("invalid status filter: %s", s)
+			}
+			q.Statuses = append(q.Statuses, s)
+		}
+	}
+
+	if v := values.Get("priority"); v != "" {
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if !models.IsValidPriority(p) {
+				return nil, fmt.Errorf("invalid priority filter: %s", p)
+			}
+			q.Priorities = append(q.Priorities, p)
+		}
+	}
+
+	if v := values.Get("due_after"); v != "" {
+		t, err := parseQueryTime(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_after: %s", v)
+		}
+		q.DueAfter = &t
+	}
+
+	if v := values.Get("due_before"); v != "" {
+		t, err := parseQueryTime(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid due_before: %s", v)
+		}
+		q.DueBefore = &t
+	}
+
+	if v := values.Get("created_by"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid created_by: %s", v)
+		}
+		q.CreatedBy = &id
+	}
+
+	// assignee=me — задачи, назначенные текущему пользователю
+	if v := values.Get("assignee"); v != "" {
+		if v == "me" {
+			v = userID
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assignee: %s", v)
+		}
+		q.Assignee = &id
+	}
+
+	// watcher=me — задачи, на которые подписан текущий пользователь
+	if v := values.Get("watcher"); v != "" {
+		if v == "me" {
+			v = userID
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watcher: %s", v)
+		}
+		q.Watcher = &id
+	}
+
+	// project_id=none — задачи вне проектов
+	if v := values.Get("project_id"); v != "" {
+		if v == "none" {
+			q.NoProject = true
+		} else {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid project_id: %s", v)
+			}
+			q.ProjectID = &id
+		}
+	}
+
+	// parent_id=none — только задачи верхнего уровня
+	if v := values.Get("parent_id"); v != "" {
+		if v == "none" {
+			q.TopLevel = true
+		} else {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parent_id: %s", v)
+			}
+			q.ParentID = &id
+		}
+	}
+
+	// label=<id,id> — задачи с метками; label_mode=any (хотя бы одна) или all (все сразу)
+	if v := values.Get("label"); v != "" {
+		seen := make(map[uuid.UUID]bool)
+		for _, raw := range strings.Split(v, ",") {
+			id, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("invalid label: %s", raw)
+			}
+			if !seen[id] {
+				seen[id] = true
+				q.Labels = append(q.Labels, id)
+			}
+		}
+	}
+
+	q.LabelMode = "any"
+	if v := values.Get("label_mode"); v != "" {
+		v = strings.ToLower(v)
+		if v != "any" && v != "all" {
+			return nil, fmt.Errorf("invalid label_mode: %s (allowed: any, all)", v)
+		}
+		q.LabelMode = v
+	}
+
+	if v := values.Get("sort"); v != "" {
+		if _, ok := taskSortExpressions[v]; !ok {
+			return nil, fmt.Errorf("invalid sort field: %s (allowed: created_at, due_date, priority)", v)
+		}
+		q.Sort = v
+	}
+
+	if v := values.Get("order"); v != "" {
+		v = strings.ToLower(v)
+		if v != "asc" && v != "desc" {
+			return nil, fmt.Errorf("invalid order: %s (allowed: asc, desc)", v)
+		}
+		q.Order = v
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit: %s", v)
+		}
+		if limit > maxTaskListLimit {
+			limit = maxTaskListLimit
+		}
+		q.Limit = limit
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := decodeTaskCursor(v)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		if cursor.Sort != q.Sort || cursor.Order != q.Order {
+			return nil, errors.New("cursor does not match sort and order parameters")
+		}
+		q.Cursor = cursor
+	}
+
+	return q, nil
+}
+
+// Принимает как RFC3339, так и просто дату (YYYY-MM-DD). now — текущий момент,
+// чтобы в сохранённом представлении можно было задать, например, просроченные задачи.
+func parseQueryTime(v string) (time.Time, error) {
+	if v == "now" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+// filters применяет фильтры без учёта курсора (используется и для подсчёта total_count)
+func (q *taskListQuery) filters(db *gorm.DB) *gorm.DB {
+	if len(q.Statuses) > 0 {
+		db = db.Where("tasks.status IN ?", q.Statuses)
+	}
+	if len(q.Priorities) > 0 {
+		db = db.Where("tasks.priority IN ?", q.Priorities)
+	}
+	if q.DueAfter != nil {
+		db = db.Where("tasks.due_date >= ?", *q.DueAfter)
+	}
+	if q.DueBefore != nil {
+		db = db.Where("tasks.due_date <= ?", *q.DueBefore)
+	}
+	if q.CreatedBy != nil {
+		db = db.Where("tasks.created_by = ?", *q.CreatedBy)
+	}
+	if q.ProjectID != nil {
+		db = db.Where("tasks.project_id = ?", *q.ProjectID)
+	}
+	if q.NoProject {
+		db = db.Where("tasks.project_id IS NULL")
+	}
+	if q.ParentID != nil {
+		db = db.Where("tasks.parent_id = ?", *q.ParentID)
+	}
+	if q.TopLevel {
+		db = db.Where("tasks.parent_id IS NULL")
+	}
+	if q.Assignee != nil {
+		db = db.Where("EXISTS (SELECT 1 FROM task_schema.task_assignees ta WHERE ta.task_id = tasks.id AND ta.user_id = ?)", *q.Assignee)
+	}
+	if q.Watcher != nil {
+		db = db.Where("EXISTS (SELECT 1 FROM task_schema.task_watchers tw WHERE tw.task_id = tasks.id AND tw.user_id = ?)", *q.Watcher)
+	}
+	if len(q.Labels) > 0 {
+		if q.LabelMode == "all" {
+			db = db.Where(`(SELECT COUNT(DISTINCT tl.label_id) FROM task_schema.task_labels tl
+				WHERE tl.task_id = tasks.id AND tl.label_id IN ?) = ?`, q.Labels, len(q.Labels))
+		} else {
+			db = db.Where("EXISTS (SELECT 1 FROM task_schema.task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id IN ?)", q.Labels)
+		}
+	}
+	return db
+}
+
+// page применяет курсор, сортировку и лимит. Запрашивается на одну запись больше,
+// чтобы понять, есть ли следующая страница.
+func (q *taskListQuery) page(db *gorm.DB) *gorm.DB {
+	expr := taskSortExpressions[q.Sort]
+
+	if q.Cursor != nil {
+		op := ">"
+		if q.Order == "desc" {
+			op = "<"
+		}
+		db = db.Where(
+			fmt.Sprintf("(%s, tasks.id) %s (CAST(? AS %s), ?)", expr, op, taskSortCasts[q.Sort]),
+			q.Cursor.Value, q.Cursor.ID,
+		)
+	}
+
+	direction := "ASC"
+	if q.Order == "desc" {
+		direction = "DESC"
+	}
+
+	return db.Order(fmt.Sprintf("%s %s, tasks.id %s", expr, direction, direction)).Limit(q.Limit + 1)
+}
+
+func (q *taskListQuery) cursorFor(task models.Task) string {
+	raw, _ := json.Marshal(q.cursorAt(task))
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// cursorAt возвращает курсор, указывающий на позицию сразу после task
+func (q *taskListQuery) cursorAt(task models.Task) *taskCursor {
+	var value string
+	switch q.Sort {
+	case "created_at":
+		value = task.CreatedAt.Format(cursorTimeLayout)
+	case "due_date":
+		if task.DueDate != nil {
+			value = task.DueDate.Format(cursorTimeLayout)
+		} else {
+			value = "infinity"
+		}
+	case "priority":
+		value = strconv.Itoa(models.PriorityWeight(task.Priority))
+	}
+
+	return &taskCursor{
+		Sort:  q.Sort,
+		Order: q.Order,
+		Value: value,
+		ID:    task.ID,
+	}
+}
+
+func decodeTaskCursor(s string) (*taskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor taskCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, err
+	}
+	if _, ok := taskSortExpressions[cursor.Sort]; !ok || cursor.ID == uuid.Nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	// Значение попадает в CAST, поэтому проверяем его заранее
+	switch taskSortCasts[cursor.Sort] {
+	case "integer":
+		if _, err := strconv.Atoi(cursor.Value); err != nil {
+			return nil, err
+		}
+	case "timestamp":
+		if cursor.Value != "infinity" {
+			if _, err := time.Parse(cursorTimeLayout, cursor.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &cursor, nil
+}
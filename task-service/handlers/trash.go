@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// setSubtreeDeletedAt помечает (или снимает пометку) удаления у всех потомков задачи.
+// При восстановлении затрагиваются только потомки, удалённые вместе с задачей (тот же deleted_at).
+func setSubtreeDeletedAt(tx *gorm.DB, taskID uuid.UUID, from, to *time.Time) error {
+	return tx.Exec(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM task_schema.tasks WHERE parent_id = ? AND deleted_at IS NOT DISTINCT FROM ?
+			UNION ALL
+			SELECT t.id FROM task_schema.tasks t
+			JOIN subtree s ON t.parent_id = s.id
+			WHERE t.deleted_at IS NOT DISTINCT FROM ?
+		)
+		UPDATE task_schema.tasks SET deleted_at = ? WHERE id IN (SELECT id FROM subtree)`,
+		taskID, from, from, to).Error
+}
+
+// findTrashedTask загружает задачу из корзины, если пользователь может её изменять.
+// При ошибке ответ уже отправлен.
+func (h *TaskHandler) findTrashedTask(c *gin.Context, tx *gorm.DB, taskID, userID uuid.UUID, role string) (*models.Task, bool) {
+	var task models.Task
+	err := tx.Unscoped().
+		Scopes(editableTasks(userID, role)).
+		Where("tasks.id = ? AND tasks.deleted_at IS NOT NULL", taskID).
+		First(&task).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Task not found in trash")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return nil, false
+	}
+	return &task, true
+}
+
+// GetTrash возвращает удалённые задачи, которые пользователь может восстановить.
+// Подзадачи, удалённые вместе с родителем, не показываются отдельно.
+func (h *TaskHandler) GetTrash(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	tasks := []models.Task{}
+	err := h.DB.Unscoped().
+		Scopes(editableTasks(userUUID, role)).
+		Where("tasks.deleted_at IS NOT NULL").
+		Where(`NOT EXISTS (
+			SELECT 1 FROM task_schema.tasks p
+			WHERE p.id = tasks.parent_id AND p.deleted_at IS NOT NULL
+		)`).
+		Order("tasks.deleted_at DESC").
+		Find(&tasks).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch trash")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    tasks,
+	})
+}
+
+// RestoreTask возвращает задачу из корзины вместе с подзадачами, удалёнными одновременно с ней
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTrashedTask(c, h.DB, taskUUID, userUUID, role)
+	if !ok {
+		return
+	}
+
+	// Подзадачу нельзя вернуть в удалённого родителя
+	if task.ParentID != nil {
+		var count int64
+		if err := h.DB.Model(&models.Task{}).Where("id = ?", *task.ParentID).Count(&count).Error; err != nil {
+			respondError(c, http.StatusInternalServerError, "Failed to check parent task")
+			return
+		}
+		if count == 0 {
+			respondError(c, http.StatusConflict, "Parent task is in the trash; restore it first")
+			return
+		}
+	}
+
+	deletedAt := task.DeletedAt.Time
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(task).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		if err := setSubtreeDeletedAt(tx, task.ID, &deletedAt, nil); err != nil {
+			return err
+		}
+		return recordTaskEvent(tx, task.ID, userUUID, models.TaskEventRestored, diffTasks(nil, task))
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to restore task")
+		return
+	}
+
+	if err := h.DB.Preload("Assignees").Where("id = ?", task.ID).First(task).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch task")
+		return
+	}
+	if err := enrichTasks(h.DB, task); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to load task details")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    task,
+	})
+}
+
+// PurgeTask окончательно удаляет задачу из корзины (только администратор)
+func (h *TaskHandler) PurgeTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if role != "admin" {
+		respondError(c, http.StatusForbidden, "Only admins can permanently delete tasks")
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTrashedTask(c, h.DB, taskUUID, userUUID, role)
+	if !ok {
+		return
+	}
+
+	// Подзадачи удаляются каскадно (ON DELETE CASCADE)
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(task).Error; err != nil {
+			return err
+		}
+		return recordTaskEvent(tx, task.ID, userUUID, models.TaskEventPurged, models.FieldChanges{})
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to purge task")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Task permanently deleted"},
+	})
+}
@@ -0,0 +1,548 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"task-service/clients"
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxViewNameLength = 100
+	maxViewColumns    = 30
+	maxViewShares     = 50
+)
+
+// Параметры GET /tasks, которые можно сохранить в представлении; limit и cursor не сохраняются
+var viewFilterKeys = map[string]bool{
+	"status": true, "priority": true, "due_after": true, "due_before": true,
+	"created_by": true, "assignee": true, "watcher": true, "project_id": true, "parent_id": true,
+	"label": true, "label_mode": true, "sort": true, "order": true,
+}
+
+type ViewHandler struct {
+	DB   *gorm.DB
+	Auth *clients.AuthClient
+}
+
+func NewViewHandler(db *gorm.DB, authClient *clients.AuthClient) *ViewHandler {
+	return &ViewHandler{DB: db, Auth: authClient}
+}
+
+type SaveViewRequest struct {
+	Name       string             `json:"name" binding:"required"`
+	Filters    models.ViewFilters `json:"filters"`
+	Columns    models.ViewColumns `json:"columns"`
+	SharedWith []uuid.UUID        `json:"shared_with"`
+	// Сделать представление представлением по умолчанию для владельца
+	Default bool `json:"default"`
+}
+
+const viewSharedCondition = `EXISTS (
+	SELECT 1 FROM task_schema.task_view_shares s WHERE s.view_id = task_views.id AND s.user_id = ?
+)`
+
+// accessibleViews — свои представления и открытые пользователю
+func accessibleViews(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(task_views.owner_id = ? OR "+viewSharedCondition+")", userID, userID)
+	}
+}
+
+// validateViewFilters проверяет ключи и значения фильтров тем же разбором, что и у GET /tasks
+func validateViewFilters(filters models.ViewFilters, userID uuid.UUID) error {
+	values := url.Values{}
+	for k, v := range filters {
+		if !viewFilterKeys[k] {
+			return fmt.Errorf("unsupported filter: %s", k)
+		}
+		values.Set(k, v)
+	}
+	_, err := parseTaskListValues(values, userID.String())
+	return err
+}
+
+func validateViewColumns(columns models.ViewColumns) error {
+	if len(columns) > maxViewColumns {
+		return fmt.Errorf("at most %d columns are allowed", maxViewColumns)
+	}
+	seen := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if strings.TrimSpace(col) == "" || len(col) > 50 {
+			return errors.New("column names must be non-empty and at most 50 characters")
+		}
+		if seen[col] {
+			return fmt.Errorf("duplicate column: %s", col)
+		}
+		seen[col] = true
+	}
+	return nil
+}
+
+func findAccessibleView(db *gorm.DB, viewID, userID uuid.UUID) (*models.TaskView, error) {
+	var view models.TaskView
+	if err := db.Scopes(accessibleViews(userID)).Where("task_views.id = ?", viewID).First(&view).Error; err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// defaultView возвращает представление пользователя по умолчанию или nil. Представление,
+// которое перестало быть доступным (владелец закрыл доступ), не применяется.
+func defaultView(db *gorm.DB, userID uuid.UUID) (*models.TaskView, error) {
+	var view models.TaskView
+	err := db.Scopes(accessibleViews(userID)).
+		Joins("JOIN task_schema.task_view_defaults d ON d.view_id = task_views.id AND d.user_id = ?", userID).
+		First(&view).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// hasListFilters — задан ли в запросе хоть один параметр списка, кроме limit и cursor
+func hasListFilters(values url.Values) bool {
+	for k := range values {
+		if k != "limit" && k != "cursor" {
+			return true
+		}
+	}
+	return false
+}
+
+// taskListQueryWithView разбирает параметры списка задач с учётом представления: view=<id>
+// применяет сохранённые фильтры, явные параметры запроса важнее них. Если useDefault и
+// параметров нет, применяется представление по умолчанию; view=none его отключает.
+// При ошибке ответ уже отправлен.
+func taskListQueryWithView(c *gin.Context, db *gorm.DB, userID uuid.UUID, useDefault bool) (*taskListQuery, *models.TaskView, bool) {
+	values := c.Request.URL.Query()
+	viewParam := values.Get("view")
+	values.Del("view")
+
+	var view *models.TaskView
+	var err error
+	switch {
+	case viewParam == "none":
+	case viewParam != "":
+		viewID, parseErr := uuid.Parse(viewParam)
+		if parseErr != nil {
+			respondError(c, http.StatusBadRequest, "Invalid view ID")
+			return nil, nil, false
+		}
+		view, err = findAccessibleView(db, viewID, userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "View not found")
+			return nil, nil, false
+		}
+	case useDefault && !hasListFilters(values):
+		view, err = defaultView(db, userID)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch view")
+		return nil, nil, false
+	}
+
+	if view != nil {
+		merged := url.Values{}
+		for k, v := range view.Filters {
+			merged.Set(k, v)
+		}
+		for k, v := range values {
+			merged[k] = v
+		}
+		values = merged
+	}
+
+	query, err := parseTaskListValues(values, userID.String())
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return nil, nil, false
+	}
+	return query, view, true
+}
+
+// attachViewDetails заполняет IsDefault, а для своих представлений — SharedWith
+func attachViewDetails(db *gorm.DB, userID uuid.UUID, views []models.TaskView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	var def models.TaskViewDefault
+	err := db.Where("user_id = ?", userID).First(&def).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	var owned []uuid.UUID
+	for i := range views {
+		views[i].IsDefault = views[i].ID == def.ViewID
+		if views[i].OwnerID == userID {
+			owned = append(owned, views[i].ID)
+		}
+	}
+	if len(owned) == 0 {
+		return nil
+	}
+
+	var shares []models.TaskViewShare
+	if err := db.Where("view_id IN ?", owned).Order("created_at").Find(&shares).Error; err != nil {
+		return err
+	}
+	byView := make(map[uuid.UUID][]uuid.UUID)
+	for _, s := range shares {
+		byView[s.ViewID] = append(byView[s.ViewID], s.UserID)
+	}
+	for i := range views {
+		if views[i].OwnerID == userID {
+			views[i].SharedWith = byView[views[i].ID]
+			if views[i].SharedWith == nil {
+				views[i].SharedWith = []uuid.UUID{}
+			}
+		}
+	}
+	return nil
+}
+
+// bindViewRequest разбирает и проверяет тело запроса. При ошибке ответ уже отправлен.
+func (h *ViewHandler) bindViewRequest(c *gin.Context, userID uuid.UUID) (*SaveViewRequest, bool) {
+	var req SaveViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return nil, false
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondError(c, http.StatusBadRequest, "View name cannot be empty")
+		return nil, false
+	}
+	if len([]rune(req.Name)) > maxViewNameLength {
+		respondError(c, http.StatusBadRequest, "View name is too long")
+		return nil, false
+	}
+	if err := validateViewFilters(req.Filters, userID); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid filters: "+err.Error())
+		return nil, false
+	}
+	if err := validateViewColumns(req.Columns); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid columns: "+err.Error())
+		return nil, false
+	}
+	if req.Filters == nil {
+		req.Filters = models.ViewFilters{}
+	}
+	if req.Columns == nil {
+		req.Columns = models.ViewColumns{}
+	}
+
+	// Делиться с самим собой незачем; повторы убираются
+	seen := map[uuid.UUID]bool{userID: true}
+	shared := make([]uuid.UUID, 0, len(req.SharedWith))
+	for _, id := range req.SharedWith {
+		if !seen[id] {
+			seen[id] = true
+			shared = append(shared, id)
+		}
+	}
+	if len(shared) > maxViewShares {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("A view can be shared with at most %d users", maxViewShares))
+		return nil, false
+	}
+	if len(shared) > 0 {
+		users, err := h.Auth.LookupUsers(c.Request.Context(), shared)
+		if err != nil {
+			respondError(c, http.StatusBadGateway, "Failed to verify users")
+			return nil, false
+		}
+		var unknown []string
+		for _, id := range shared {
+			if _, exists := users[id]; !exists {
+				unknown = append(unknown, id.String())
+			}
+		}
+		if len(unknown) > 0 {
+			respondError(c, http.StatusBadRequest, "Unknown user IDs: "+strings.Join(unknown, ", "))
+			return nil, false
+		}
+	}
+	req.SharedWith = shared
+	return &req, true
+}
+
+// saveShares заменяет список пользователей, которым открыто представление. У тех, кто
+// потерял доступ, представление перестаёт быть представлением по умолчанию.
+func saveShares(tx *gorm.DB, view *models.TaskView, users []uuid.UUID) error {
+	if err := tx.Where("view_id = ?", view.ID).Delete(&models.TaskViewShare{}).Error; err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		shares := make([]models.TaskViewShare, len(users))
+		for i, id := range users {
+			shares[i] = models.TaskViewShare{ViewID: view.ID, UserID: id}
+		}
+		if err := tx.Create(&shares).Error; err != nil {
+			return err
+		}
+	}
+
+	keep := append([]uuid.UUID{view.OwnerID}, users...)
+	return tx.Where("view_id = ? AND user_id NOT IN ?", view.ID, keep).Delete(&models.TaskViewDefault{}).Error
+}
+
+func setDefaultView(tx *gorm.DB, userID, viewID uuid.UUID) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"view_id"}),
+	}).Create(&models.TaskViewDefault{UserID: userID, ViewID: viewID}).Error
+}
+
+// findView загружает доступное пользователю представление. ownerOnly — только своё.
+// При ошибке ответ уже отправлен.
+func (h *ViewHandler) findView(c *gin.Context, userID uuid.UUID, ownerOnly bool) (*models.TaskView, bool) {
+	viewUUID, ok := uuidParam(c, "id", "view")
+	if !ok {
+		return nil, false
+	}
+
+	view, err := findAccessibleView(h.DB, viewUUID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "View not found")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch view")
+		return nil, false
+	}
+	if ownerOnly && view.OwnerID != userID {
+		respondError(c, http.StatusForbidden, "Only the owner can change a view")
+		return nil, false
+	}
+	return view, true
+}
+
+// GetViews возвращает свои представления и открытые пользователю
+func (h *ViewHandler) GetViews(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	views := []models.TaskView{}
+	if err := h.DB.Scopes(accessibleViews(userUUID)).Order("lower(task_views.name)").Find(&views).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch views")
+		return
+	}
+	if err := attachViewDetails(h.DB, userUUID, views); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch views")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    views,
+	})
+}
+
+func (h *ViewHandler) GetView(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.findView(c, userUUID, false)
+	if !ok {
+		return
+	}
+
+	views := []models.TaskView{*view}
+	if err := attachViewDetails(h.DB, userUUID, views); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch view")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    views[0],
+	})
+}
+
+func (h *ViewHandler) CreateView(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindViewRequest(c, userUUID)
+	if !ok {
+		return
+	}
+
+	view := models.TaskView{
+		OwnerID: userUUID,
+		Name:    req.Name,
+		Filters: req.Filters,
+		Columns: req.Columns,
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&view).Error; err != nil {
+			return err
+		}
+		if err := saveShares(tx, &view, req.SharedWith); err != nil {
+			return err
+		}
+		if req.Default {
+			return setDefaultView(tx, userUUID, view.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, "A view with this name already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to create view")
+		return
+	}
+
+	view.SharedWith = req.SharedWith
+	view.IsDefault = req.Default
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    view,
+	})
+}
+
+// UpdateView полностью заменяет имя, фильтры, колонки и список пользователей представления
+func (h *ViewHandler) UpdateView(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.findView(c, userUUID, true)
+	if !ok {
+		return
+	}
+
+	req, ok := h.bindViewRequest(c, userUUID)
+	if !ok {
+		return
+	}
+
+	view.Name = req.Name
+	view.Filters = req.Filters
+	view.Columns = req.Columns
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(view).Select("name", "filters", "columns", "updated_at").Updates(view).Error; err != nil {
+			return err
+		}
+		if err := saveShares(tx, view, req.SharedWith); err != nil {
+			return err
+		}
+		if req.Default {
+			return setDefaultView(tx, userUUID, view.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, "A view with this name already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to update view")
+		return
+	}
+
+	views := []models.TaskView{*view}
+	if err := attachViewDetails(h.DB, userUUID, views); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch view")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    views[0],
+	})
+}
+
+func (h *ViewHandler) DeleteView(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.findView(c, userUUID, true)
+	if !ok {
+		return
+	}
+
+	// Доступы и отметки «по умолчанию» удаляются каскадно
+	if err := h.DB.Delete(view).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete view")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "View deleted successfully"},
+	})
+}
+
+// SetDefaultView делает представление (своё или открытое пользователю) представлением по умолчанию
+func (h *ViewHandler) SetDefaultView(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.findView(c, userUUID, false)
+	if !ok {
+		return
+	}
+
+	if err := setDefaultView(h.DB, userUUID, view.ID); err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to set default view")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Default view set", "view_id": view.ID},
+	})
+}
+
+// ClearDefaultView снимает отметку «по умолчанию» с представления
+func (h *ViewHandler) ClearDefaultView(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	view, ok := h.findView(c, userUUID, false)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Where("user_id = ? AND view_id = ?", userUUID, view.ID).Delete(&models.TaskViewDefault{}).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to clear default view")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Default view cleared"},
+	})
+}
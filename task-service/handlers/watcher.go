@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxWatchersPerRequest = 50
+
+// Поля задачи, об изменении которых сообщается наблюдателям
+var watchedTaskFields = []struct{ Field, Kind string }{
+	{"status", models.WatchStatusChanged},
+	{"due_date", models.WatchDueDateChanged},
+}
+
+type WatchTaskRequest struct {
+	// Пусто — подписать текущего пользователя
+	UserIDs []uuid.UUID `json:"user_ids"`
+}
+
+// queueWatcherNotifications ставит в очередь уведомления о смене статуса и срока.
+// Вызывается в транзакции изменения задачи, поэтому уведомление не теряется и не уходит при откате.
+func queueWatcherNotifications(tx *gorm.DB, taskID, actorID uuid.UUID, changes models.FieldChanges) error {
+	for _, watched := range watchedTaskFields {
+		change, changed := changes[watched.Field]
+		if !changed {
+			continue
+		}
+		notification := models.TaskWatchNotification{
+			TaskID:  taskID,
+			ActorID: actorID,
+			Kind:    watched.Kind,
+			Changes: models.FieldChanges{watched.Field: change},
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueCommentNotification ставит в очередь уведомление о новом комментарии
+func queueCommentNotification(tx *gorm.DB, comment *models.TaskComment) error {
+	return tx.Create(&models.TaskWatchNotification{
+		TaskID:    comment.TaskID,
+		ActorID:   comment.AuthorID,
+		Kind:      models.WatchCommented,
+		Changes:   models.FieldChanges{},
+		CommentID: &comment.ID,
+	}).Error
+}
+
+func (h *TaskHandler) respondWatchers(c *gin.Context, code int, taskID uuid.UUID) {
+	var watchers []models.TaskWatcher
+	if err := h.DB.Where("task_id = ?", taskID).Order("created_at, user_id").Find(&watchers).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch watchers")
+		return
+	}
+
+	c.JSON(code, SuccessResponse{
+		Success: true,
+		Data:    watchers,
+	})
+}
+
+func (h *TaskHandler) GetWatchers(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	h.respondWatchers(c, http.StatusOK, task.ID)
+}
+
+// WatchTask подписывает на задачу текущего пользователя (пустое тело) или перечисленных
+// пользователей. Подписаться самому может любой, кто видит задачу; подписывать других —
+// те, кто может назначать исполнителей. Наблюдатель получает доступ к задаче на чтение.
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req WatchTaskRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+	if len(req.UserIDs) > maxWatchersPerRequest {
+		respondError(c, http.StatusBadRequest, "Too many watchers in one request")
+		return
+	}
+
+	level := accessView
+	for _, id := range req.UserIDs {
+		if id != userUUID {
+			level = accessEdit
+			break
+		}
+	}
+	task, ok := h.findTask(c, taskUUID, userUUID, role, level)
+	if !ok {
+		return
+	}
+
+	watchers := []models.TaskWatcher{{TaskID: task.ID, UserID: userUUID, Source: models.WatchSourceManual}}
+	if len(req.UserIDs) > 0 {
+		if level == accessEdit {
+			users, err := h.Auth.LookupUsers(c.Request.Context(), req.UserIDs)
+			if err != nil {
+				respondError(c, http.StatusBadGateway, "Failed to verify users")
+				return
+			}
+
+			var unknown []string
+			for _, id := range req.UserIDs {
+				if _, exists := users[id]; !exists && id != userUUID {
+					unknown = append(unknown, id.String())
+				}
+			}
+			if len(unknown) > 0 {
+				respondError(c, http.StatusBadRequest, "Unknown user IDs: "+strings.Join(unknown, ", "))
+				return
+			}
+		}
+
+		watchers = make([]models.TaskWatcher, 0, len(req.UserIDs))
+		for _, id := range req.UserIDs {
+			watchers = append(watchers, models.TaskWatcher{TaskID: task.ID, UserID: id, Source: models.WatchSourceManual})
+		}
+	}
+
+	// Повторная подписка не считается ошибкой. Автоматическая подписка становится ручной
+	// и больше не снимается вместе с назначением.
+	err := h.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"source": models.WatchSourceManual}),
+	}).Create(&watchers).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to watch task")
+		return
+	}
+
+	h.respondWatchers(c, http.StatusOK, task.ID)
+}
+
+// UnwatchTask отписывает пользователя от задачи; userId=me — текущий пользователь.
+// Отписаться сам может любой наблюдатель, отписать другого — тот, кто может назначать исполнителей.
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	watcherUUID := userUUID
+	if c.Param("userId") != "me" {
+		if watcherUUID, ok = uuidParam(c, "userId", "user"); !ok {
+			return
+		}
+	}
+
+	level := accessEdit
+	if watcherUUID == userUUID {
+		level = accessView
+	}
+	task, ok := h.findTask(c, taskUUID, userUUID, role, level)
+	if !ok {
+		return
+	}
+
+	result := h.DB.Where("task_id = ? AND user_id = ?", task.ID, watcherUUID).Delete(&models.TaskWatcher{})
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to unwatch task")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "User is not watching this task")
+		return
+	}
+
+	h.respondWatchers(c, http.StatusOK, task.ID)
+}
@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-service/models"
+	"task-service/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxWebhooksPerUser   = 20
+	minWebhookSecret     = 16
+	maxWebhookSecret     = 256
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// Событие вебхука для каждого действия журнала; restored, purged и смена исполнителей не отправляются
+var taskEventWebhooks = map[models.TaskEventAction]string{
+	models.TaskEventCreated:       models.WebhookTaskCreated,
+	models.TaskEventUpdated:       models.WebhookTaskUpdated,
+	models.TaskEventStatusChanged: models.WebhookTaskStatusChanged,
+	models.TaskEventDeleted:       models.WebhookTaskDeleted,
+}
+
+type WebhookHandler struct {
+	DB *gorm.DB
+}
+
+func NewWebhookHandler(db *gorm.DB) *WebhookHandler {
+	return &WebhookHandler{DB: db}
+}
+
+type SaveWebhookRequest struct {
+	URL string `json:"url" binding:"required"`
+	// При изменении пустой секрет оставляет прежний
+	Secret string   `json:"secret"`
+	Events []string `json:"events" binding:"required"`
+	Active *bool    `json:"active"`
+	// События по всем задачам; только для администратора
+	AllTasks bool `json:"all_tasks"`
+}
+
+// WebhookEventPayload — тело доставки
+type WebhookEventPayload struct {
+	// ID события журнала: у повторных доставок одного события он совпадает
+	ID         uuid.UUID           `json:"id"`
+	Event      string              `json:"event"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	ActorID    uuid.UUID           `json:"actor_id"`
+	Task       *models.Task        `json:"task"`
+	Changes    models.FieldChanges `json:"changes"`
+}
+
+// webhookEventFor выбирает событие для вебхука. Изменение, затронувшее статус, приходит
+// как task.status_changed тем, кто на него подписан, и как task.updated остальным.
+func webhookEventFor(hook *models.Webhook, event *models.TaskEvent) string {
+	name := taskEventWebhooks[event.Action]
+	if event.Action == models.TaskEventUpdated {
+		if _, changed := event.Changes["status"]; changed && hook.Events.Has(models.WebhookTaskStatusChanged) {
+			return models.WebhookTaskStatusChanged
+		}
+	}
+	if hook.Events.Has(name) {
+		return name
+	}
+	return ""
+}
+
+// queueWebhookDeliveries добавляет доставки события подписанным вебхукам в транзакции изменения
+// задачи. Вебхук без all_tasks получает только события задач, видимых его владельцу.
+func queueWebhookDeliveries(tx *gorm.DB, event *models.TaskEvent) error {
+	name, ok := taskEventWebhooks[event.Action]
+	if !ok {
+		return nil
+	}
+	names := []string{name}
+	if _, changed := event.Changes["status"]; changed && event.Action == models.TaskEventUpdated {
+		names = append(names, models.WebhookTaskStatusChanged)
+	}
+
+	// Подписчики и проверка видимости — одним запросом: вебхук без all_tasks подходит,
+	// только если его владелец может читать задачу. Задача может быть уже в корзине (событие deleted).
+	var hooks []models.Webhook
+	err := tx.Joins("JOIN task_schema.tasks ON tasks.id = ?", event.TaskID).
+		Where("webhooks.active AND jsonb_exists_any(webhooks.events, string_to_array(?, ','))", strings.Join(names, ",")).
+		Where("(webhooks.all_tasks OR " + viewConditionFor("webhooks.owner_id") + ")").
+		Find(&hooks).Error
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	var task models.Task
+	if err := tx.Unscoped().Preload("Assignees").Where("id = ?", event.TaskID).First(&task).Error; err != nil {
+		return err
+	}
+
+	occurredAt := event.CreatedAt
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	for i := range hooks {
+		hook := &hooks[i]
+		eventName := webhookEventFor(hook, event)
+		if eventName == "" {
+			continue
+		}
+		payload, err := json.Marshal(WebhookEventPayload{
+			ID:         event.ID,
+			Event:      eventName,
+			OccurredAt: occurredAt,
+			ActorID:    event.ActorID,
+			Task:       &task,
+			Changes:    event.Changes,
+		})
+		if err != nil {
+			return err
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       event.ID,
+			Event:         eventName,
+			TaskID:        task.ID,
+			Payload:       payload,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bindWebhookRequest проверяет тело запроса. При ошибке ответ уже отправлен.
+func bindWebhookRequest(c *gin.Context, role string, requireSecret bool) (*SaveWebhookRequest, bool) {
+	var req SaveWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return nil, false
+	}
+
+	req.URL = strings.TrimSpace(req.URL)
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	if req.Secret != "" || requireSecret {
+		if len(req.Secret) < minWebhookSecret || len(req.Secret) > maxWebhookSecret {
+			respondError(c, http.StatusBadRequest, "secret must be between "+strconv.Itoa(minWebhookSecret)+
+				" and "+strconv.Itoa(maxWebhookSecret)+" characters")
+			return nil, false
+		}
+	}
+
+	if len(req.Events) == 0 {
+		respondError(c, http.StatusBadRequest, "events must not be empty")
+		return nil, false
+	}
+	seen := map[string]bool{}
+	events := make([]string, 0, len(req.Events))
+	for _, e := range req.Events {
+		if !models.IsValidWebhookEvent(e) {
+			respondError(c, http.StatusBadRequest, "Unknown event: "+e+
+				" (allowed: task.created, task.updated, task.status_changed, task.deleted)")
+			return nil, false
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	req.Events = events
+
+	if req.AllTasks && role != "admin" {
+		respondError(c, http.StatusForbidden, "Only admins can subscribe to events of all tasks")
+		return nil, false
+	}
+	return &req, true
+}
+
+// findWebhook загружает вебхук; доступ есть у владельца и администратора. При ошибке ответ уже отправлен.
+func (h *WebhookHandler) findWebhook(c *gin.Context, userID uuid.UUID, role string) (*models.Webhook, bool) {
+	webhookUUID, ok := uuidParam(c, "id", "webhook")
+	if !ok {
+		return nil, false
+	}
+
+	var hook models.Webhook
+	query := h.DB.Where("id = ?", webhookUUID)
+	if role != "admin" {
+		query = query.Where("owner_id = ?", userID)
+	}
+	if err := query.First(&hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Webhook not found")
+			return nil, false
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch webhook")
+		return nil, false
+	}
+	return &hook, true
+}
+
+// GetWebhooks возвращает вебхуки пользователя; администратор видит все
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hooks := []models.Webhook{}
+	query := h.DB.Order("created_at")
+	if role != "admin" {
+		query = query.Where("owner_id = ?", userUUID)
+	}
+	if err := query.Find(&hooks).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    hooks,
+	})
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findWebhook(c, userUUID, role)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    hook,
+	})
+}
+
+// CreateWebhook регистрирует вебхук. Секрет в ответах не возвращается.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindWebhookRequest(c, role, true)
+	if !ok {
+		return
+	}
+
+	var count int64
+	if err := h.DB.Model(&models.Webhook{}).Where("owner_id = ?", userUUID).Count(&count).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+	if count >= maxWebhooksPerUser {
+		respondError(c, http.StatusConflict, "Webhook limit reached ("+strconv.Itoa(maxWebhooksPerUser)+" per user)")
+		return
+	}
+
+	hook := models.Webhook{
+		OwnerID:  userUUID,
+		URL:      req.URL,
+		Secret:   req.Secret,
+		Events:   req.Events,
+		AllTasks: req.AllTasks,
+		Active:   req.Active == nil || *req.Active,
+	}
+	if err := h.DB.Create(&hook).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    hook,
+	})
+}
+
+// UpdateWebhook заменяет адрес, события и флаги вебхука; пустой secret оставляет прежний
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findWebhook(c, userUUID, role)
+	if !ok {
+		return
+	}
+
+	req, ok := bindWebhookRequest(c, role, false)
+	if !ok {
+		return
+	}
+
+	hook.URL = req.URL
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+	hook.Events = req.Events
+	hook.AllTasks = req.AllTasks
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+
+	err := h.DB.Model(hook).Updates(map[string]interface{}{
+		"url":       hook.URL,
+		"secret":    hook.Secret,
+		"events":    hook.Events,
+		"all_tasks": hook.AllTasks,
+		"active":    hook.Active,
+	}).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    hook,
+	})
+}
+
+// DeleteWebhook удаляет вебхук вместе с журналом доставок
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findWebhook(c, userUUID, role)
+	if !ok {
+		return
+	}
+
+	if err := h.DB.Delete(hook).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Webhook deleted successfully"},
+	})
+}
+
+// GetDeliveries возвращает журнал доставок вебхука, от новых к старым. ?status фильтрует
+// по состоянию (pending, delivered, dead), ?limit — до 200 записей.
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findWebhook(c, userUUID, role)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveryLimit {
+			respondError(c, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxDeliveryLimit))
+			return
+		}
+		limit = n
+	}
+
+	query := h.DB.Where("webhook_id = ?", hook.ID)
+	if v := c.Query("status"); v != "" {
+		if !models.IsValidDeliveryStatus(v) {
+			respondError(c, http.StatusBadRequest, "status must be one of: pending, delivered, dead")
+			return
+		}
+		query = query.Where("status = ?", v)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := query.Order("created_at DESC, id").Limit(limit).Find(&deliveries).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    deliveries,
+	})
+}
+
+// RedeliverDelivery ставит доставку, исчерпавшую попытки, обратно в очередь с нуля попыток
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	hook, ok := h.findWebhook(c, userUUID, role)
+	if !ok {
+		return
+	}
+
+	deliveryUUID, ok := uuidParam(c, "deliveryId", "delivery")
+	if !ok {
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := h.DB.Where("id = ? AND webhook_id = ?", deliveryUUID, hook.ID).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Delivery not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch delivery")
+		return
+	}
+
+	// Условие на статус в самом UPDATE: доставка могла измениться после чтения
+	now := time.Now()
+	result := h.DB.Model(&delivery).Where("status = ?", models.DeliveryDead).Updates(map[string]interface{}{
+		"status":          models.DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+	})
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to redeliver")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondErrorWithDetails(c, http.StatusConflict, "Only failed deliveries can be redelivered", gin.H{
+			"status": delivery.Status,
+		})
+		return
+	}
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Success: true,
+		Data:    delivery,
+	})
+}
@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"task-service/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Одна ручная запись — не больше суток
+const maxWorklogMinutes = 24 * 60
+
+type StartTimerRequest struct {
+	Note string `json:"note"`
+}
+
+type StopTimerRequest struct {
+	Note *string `json:"note"`
+	// Подтверждение остановки таймера, который шёл дольше TASK_TIMER_CONFIRM_HOURS
+	Confirm bool `json:"confirm"`
+	// Исправленное время окончания, если таймер забыли остановить
+	EndedAt *time.Time `json:"ended_at"`
+}
+
+type CreateWorklogRequest struct {
+	DurationMinutes int `json:"duration_minutes" binding:"required,min=1"`
+	// Начало работы; по умолчанию — сейчас минус длительность
+	StartedAt *time.Time `json:"started_at"`
+	Note      string     `json:"note"`
+}
+
+// TimeTotal — строка агрегата затраченного времени
+type TimeTotal struct {
+	UserID       *uuid.UUID `json:"user_id,omitempty"`
+	TaskID       *uuid.UUID `json:"task_id,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	WeekStart    *time.Time `json:"week_start,omitempty"`
+	TotalSeconds int64      `json:"total_seconds"`
+	Entries      int64      `json:"entries"`
+}
+
+// bindOptionalJSON разбирает тело запроса, если оно есть. При ошибке ответ уже отправлен.
+func bindOptionalJSON(c *gin.Context, req interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func runningTimer(db *gorm.DB, userID uuid.UUID) (*models.TaskWorklog, error) {
+	var timer models.TaskWorklog
+	err := db.Where("user_id = ? AND ended_at IS NULL", userID).First(&timer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &timer, nil
+}
+
+func respondTimerRunning(c *gin.Context, running *models.TaskWorklog) {
+	respondErrorWithDetails(c, http.StatusConflict, "Another timer is already running; stop it first",
+		gin.H{"running": running})
+}
+
+// GetCurrentTimer возвращает запущенный таймер пользователя или null
+func (h *TaskHandler) GetCurrentTimer(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	timer, err := runningTimer(h.DB, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch timer")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    timer,
+	})
+}
+
+// StartTimer запускает таймер по задаче. У пользователя может идти только один таймер.
+func (h *TaskHandler) StartTimer(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req StartTimerRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	// Учитывать время могут те, кто может менять статус задачи
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessStatus)
+	if !ok {
+		return
+	}
+	if task.Status == models.StatusCompleted || task.Status == models.StatusCancelled {
+		respondError(c, http.StatusConflict, "Cannot start a timer on a completed or cancelled task")
+		return
+	}
+
+	running, err := runningTimer(h.DB, userUUID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch timer")
+		return
+	}
+	if running != nil {
+		respondTimerRunning(c, running)
+		return
+	}
+
+	timer := models.TaskWorklog{
+		TaskID:    task.ID,
+		UserID:    userUUID,
+		Source:    models.WorklogTimer,
+		StartedAt: time.Now(),
+		Note:      req.Note,
+	}
+	if err := h.DB.Create(&timer).Error; err != nil {
+		// Таймер успел запуститься параллельным запросом: сработал уникальный индекс
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			if running, _ := runningTimer(h.DB, userUUID); running != nil {
+				respondTimerRunning(c, running)
+				return
+			}
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to start timer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    timer,
+	})
+}
+
+// StopTimer останавливает таймер пользователя по задаче. Если таймер шёл дольше порога,
+// нужно передать confirm: true или исправленное ended_at.
+func (h *TaskHandler) StopTimer(c *gin.Context) {
+	userUUID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req StopTimerRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	// Таймер принадлежит пользователю, поэтому остановить его можно и без доступа к задаче
+	var timer models.TaskWorklog
+	err := h.DB.Where("user_id = ? AND task_id = ? AND ended_at IS NULL", userUUID, taskUUID).First(&timer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(c, http.StatusNotFound, "No running timer on this task")
+		return
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch timer")
+		return
+	}
+
+	now := time.Now()
+	endedAt := now
+	if req.EndedAt != nil {
+		if !req.EndedAt.After(timer.StartedAt) || req.EndedAt.After(now) {
+			respondError(c, http.StatusBadRequest, "ended_at must be after the timer start and not in the future")
+			return
+		}
+		endedAt = *req.EndedAt
+	}
+
+	elapsed := endedAt.Sub(timer.StartedAt)
+	threshold := time.Duration(h.Config.TimerConfirmHours) * time.Hour
+	if threshold > 0 && elapsed > threshold && !req.Confirm && req.EndedAt == nil {
+		respondErrorWithDetails(c, http.StatusConflict,
+			fmt.Sprintf("Timer has been running for more than %d hours; send confirm=true to keep it or ended_at to correct it", h.Config.TimerConfirmHours),
+			gin.H{
+				"started_at":            timer.StartedAt,
+				"elapsed_seconds":       int64(elapsed.Seconds()),
+				"confirm_after_seconds": int64(threshold.Seconds()),
+			})
+		return
+	}
+
+	duration := int64(elapsed.Seconds())
+	updates := map[string]interface{}{
+		"ended_at":         endedAt,
+		"duration_seconds": duration,
+	}
+	if req.Note != nil {
+		updates["note"] = *req.Note
+	}
+	// Условие ended_at IS NULL защищает от двойной остановки параллельными запросами
+	result := h.DB.Model(&timer).Where("ended_at IS NULL").Updates(updates)
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to stop timer")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "No running timer on this task")
+		return
+	}
+	timer.EndedAt = &endedAt
+	timer.DurationSeconds = &duration
+	if req.Note != nil {
+		timer.Note = *req.Note
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    timer,
+	})
+}
+
+// GetWorklogs возвращает записи времени по задаче, включая запущенные таймеры
+func (h *TaskHandler) GetWorklogs(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	var worklogs []models.TaskWorklog
+	if err := h.DB.Where("task_id = ?", task.ID).Order("started_at DESC").Find(&worklogs).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to fetch worklogs")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    worklogs,
+	})
+}
+
+// CreateWorklog добавляет ручную запись затраченного времени
+func (h *TaskHandler) CreateWorklog(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	var req CreateWorklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid request data: "+err.Error())
+		return
+	}
+	if req.DurationMinutes > maxWorklogMinutes {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("duration_minutes must not exceed %d", maxWorklogMinutes))
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessStatus)
+	if !ok {
+		return
+	}
+
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	now := time.Now()
+	startedAt := now.Add(-duration)
+	if req.StartedAt != nil {
+		startedAt = *req.StartedAt
+	}
+	endedAt := startedAt.Add(duration)
+	if endedAt.After(now) {
+		respondError(c, http.StatusBadRequest, "A worklog cannot end in the future")
+		return
+	}
+
+	seconds := int64(duration.Seconds())
+	worklog := models.TaskWorklog{
+		TaskID:          task.ID,
+		UserID:          userUUID,
+		Source:          models.WorklogManual,
+		StartedAt:       startedAt,
+		EndedAt:         &endedAt,
+		DurationSeconds: &seconds,
+		Note:            req.Note,
+	}
+	if err := h.DB.Create(&worklog).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to create worklog")
+		return
+	}
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Success: true,
+		Data:    worklog,
+	})
+}
+
+// DeleteWorklog удаляет запись времени. Удалить может её автор, автор задачи или администратор.
+func (h *TaskHandler) DeleteWorklog(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	worklogUUID, ok := uuidParam(c, "worklogId", "worklog")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	var worklog models.TaskWorklog
+	if err := h.DB.Where("id = ? AND task_id = ?", worklogUUID, task.ID).First(&worklog).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Worklog not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, "Failed to fetch worklog")
+		return
+	}
+
+	if worklog.UserID != userUUID && task.CreatedBy != userUUID && role != "admin" {
+		respondError(c, http.StatusForbidden, "Only the worklog author, the task creator or an admin can delete a worklog")
+		return
+	}
+
+	if err := h.DB.Delete(&worklog).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to delete worklog")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    gin.H{"message": "Worklog deleted successfully"},
+	})
+}
+
+// GetTaskTime возвращает время, затраченное на задачу, всего и по пользователям.
+// Запущенные таймеры не учитываются.
+func (h *TaskHandler) GetTaskTime(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	taskUUID, ok := uuidParam(c, "id", "task")
+	if !ok {
+		return
+	}
+
+	task, ok := h.findTask(c, taskUUID, userUUID, role, accessView)
+	if !ok {
+		return
+	}
+
+	byUser := []TimeTotal{}
+	err := h.DB.Model(&models.TaskWorklog{}).
+		Select("user_id, SUM(duration_seconds) AS total_seconds, COUNT(*) AS entries").
+		Where("task_id = ? AND ended_at IS NOT NULL", task.ID).
+		Group("user_id").
+		Order("total_seconds DESC").
+		Scan(&byUser).Error
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to calculate time spent")
+		return
+	}
+
+	var total int64
+	for _, row := range byUser {
+		total += row.TotalSeconds
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data: gin.H{
+			"task_id":       task.ID,
+			"total_seconds": total,
+			"by_user":       byUser,
+		},
+	})
+}
+
+// GetTimeSummary считает затраченное время по видимым пользователю задачам
+// с группировкой по задаче, пользователю или неделе (group_by=task|user|week).
+// Фильтры: from, to (по началу записи), user_id (или me), project_id.
+func (h *TaskHandler) GetTimeSummary(c *gin.Context) {
+	userUUID, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	query := h.DB.Model(&models.TaskWorklog{}).
+		Joins("JOIN task_schema.tasks AS tasks ON tasks.id = task_worklogs.task_id AND tasks.deleted_at IS NULL").
+		Scopes(visibleTasks(userUUID, role)).
+		Where("task_worklogs.ended_at IS NOT NULL")
+
+	if v := c.Query("from"); v != "" {
+		t, err := parseQueryTime(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid from: "+v)
+			return
+		}
+		query = query.Where("task_worklogs.started_at >= ?", t)
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := parseQueryTime(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid to: "+v)
+			return
+		}
+		query = query.Where("task_worklogs.started_at < ?", t)
+	}
+	if v := c.Query("user_id"); v != "" {
+		id := userUUID
+		if v != "me" {
+			var err error
+			if id, err = uuid.Parse(v); err != nil {
+				respondError(c, http.StatusBadRequest, "Invalid user_id")
+				return
+			}
+		}
+		query = query.Where("task_worklogs.user_id = ?", id)
+	}
+	if v := c.Query("project_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid project_id")
+			return
+		}
+		query = query.Where("tasks.project_id = ?", id)
+	}
+
+	const totals = "SUM(task_worklogs.duration_seconds) AS total_seconds, COUNT(*) AS entries"
+	groupBy := c.DefaultQuery("group_by", "user")
+	switch groupBy {
+	case "user":
+		query = query.Select("task_worklogs.user_id, " + totals).
+			Group("task_worklogs.user_id").
+			Order("total_seconds DESC")
+	case "task":
+		query = query.Select("task_worklogs.task_id, tasks.title, " + totals).
+			Group("task_worklogs.task_id, tasks.title").
+			Order("total_seconds DESC")
+	case "week":
+		// Неделя начинается в понедельник; запись относится к неделе своего начала
+		query = query.Select("date_trunc('week', task_worklogs.started_at) AS week_start, " + totals).
+			Group("week_start").
+			Order("week_start")
+	default:
+		respondError(c, http.StatusBadRequest, "group_by must be one of: task, user, week")
+		return
+	}
+
+	rows := []TimeTotal{}
+	if err := query.Scan(&rows).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, "Failed to calculate time spent")
+		return
+	}
+
+	var total int64
+	for _, row := range rows {
+		total += row.TotalSeconds
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Success: true,
+		Data:    rows,
+		Meta:    gin.H{"group_by": groupBy, "total_seconds": total},
+	})
+}
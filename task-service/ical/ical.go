@@ -0,0 +1,105 @@
+// Package ical формирует календари iCalendar (RFC 5545): экранирование значений,
+// перенос длинных строк и окончания строк CRLF.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// Длина строки без CRLF, после которой строка переносится (RFC 5545, 3.1)
+const maxLineOctets = 75
+
+const dateTimeLayout = "20060102T150405Z"
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// EscapeText экранирует значение типа TEXT
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// FormatTime возвращает DATE-TIME в UTC
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout)
+}
+
+// Writer пишет строки календаря; ошибка записи запоминается и возвращается из Flush
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Line пишет свойство "name:value"; value должно быть уже экранировано
+func (w *Writer) Line(name, value string) {
+	w.writeFolded(name + ":" + value)
+}
+
+// Text пишет свойство с текстовым значением
+func (w *Writer) Text(name, value string) {
+	w.Line(name, EscapeText(value))
+}
+
+// Time пишет свойство со значением DATE-TIME в UTC
+func (w *Writer) Time(name string, t time.Time) {
+	w.Line(name, FormatTime(t))
+}
+
+func (w *Writer) Begin(component string) {
+	w.Line("BEGIN", component)
+}
+
+func (w *Writer) End(component string) {
+	w.Line("END", component)
+}
+
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// writeFolded переносит строку длиннее 75 октетов, не разрывая UTF-8 символы;
+// строка продолжения начинается с пробела
+func (w *Writer) writeFolded(line string) {
+	if w.err != nil {
+		return
+	}
+
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.write(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// Пробел в начале строки продолжения занимает один октет
+		limit = maxLineOctets - 1
+	}
+	w.write(line + "\r\n")
+}
+
+func (w *Writer) write(s string) {
+	if w.err == nil {
+		_, w.err = w.w.WriteString(s)
+	}
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"task-service/models"
+	"task-service/storage"
+
+	"gorm.io/gorm"
+)
+
+// Сколько вложений удаляется за один проход очистки
+const orphanAttachmentBatch = 100
+
+// PurgeOrphanAttachments удаляет вложения окончательно удалённых задач (task_id обнулён
+// внешним ключом): сначала файл в хранилище, затем запись. Если файл удалить не удалось,
+// запись остаётся до следующего запуска.
+func PurgeOrphanAttachments(ctx context.Context, db *gorm.DB, store storage.Storage) (int, error) {
+	var orphans []models.TaskAttachment
+	if err := db.Where("task_id IS NULL").Order("created_at").Limit(orphanAttachmentBatch).Find(&orphans).Error; err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for i := range orphans {
+		a := &orphans[i]
+		if err := store.Delete(ctx, a.StorageKey); err != nil {
+			log.Printf("Failed to remove stored attachment %s: %v", a.StorageKey, err)
+			continue
+		}
+		if err := db.Delete(a).Error; err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// withSessionLock выполняет fn, если удалось взять advisory-блокировку key, и возвращает,
+// была ли она взята. Блокировка держится на одном соединении без открытой транзакции:
+// fn может отправлять уведомления по сети и фиксировать результат каждой отправки сразу.
+// Запросы внутри fn выполняются через conn — то же соединение.
+func withSessionLock(ctx context.Context, db *gorm.DB, key int64, fn func(conn *gorm.DB) error) (bool, error) {
+	locked := false
+	err := db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		defer func() {
+			// Соединение возвращается в пул: блокировку нужно снять явно, даже если ctx уже отменён
+			if err := conn.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", key).Error; err != nil {
+				log.Printf("Failed to release advisory lock %#x: %v", key, err)
+			}
+		}()
+		return fn(conn)
+	})
+	return locked, err
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"task-service/broker"
+	"task-service/models"
+
+	"gorm.io/gorm"
+)
+
+// Ключ advisory-блокировки: outbox публикует одна реплика, иначе нарушится порядок событий
+const outboxLockKey int64 = 0x7461736b6f7574 // "taskout"
+
+const (
+	// Сколько событий публикуется за один проход
+	outboxBatchSize = 100
+	// Ошибка публикации пишется в лог при первой неудаче и затем раз в столько попыток
+	outboxLogEvery = 60
+	// Длина текста ошибки, сохраняемого в outbox
+	maxOutboxError = 1000
+	// Как часто удаляются старые опубликованные события
+	outboxCleanupInterval = 10 * time.Minute
+)
+
+// RelayOutbox публикует неопубликованные события в порядке id и отмечает их опубликованными.
+// Отметка ставится после того, как брокер принял событие, поэтому при сбое между публикацией
+// и коммитом событие уйдёт повторно — доставка не менее одного раза. На первой ошибке проход
+// останавливается: следующие события ждут, чтобы не обогнать неопубликованное.
+func RelayOutbox(ctx context.Context, db *gorm.DB, publisher broker.Publisher) (int, error) {
+	published := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var pending []models.OutboxEvent
+		err := tx.Where("published_at IS NULL").
+			Order("id").
+			Limit(outboxBatchSize).
+			Find(&pending).Error
+		if err != nil {
+			return err
+		}
+
+		var ids []int64
+		for i := range pending {
+			event := &pending[i]
+			payload, err := json.Marshal(event.Payload)
+			if err != nil {
+				return err
+			}
+
+			pubErr := publisher.Publish(ctx, broker.Message{
+				ID:         event.EventID,
+				Type:       event.EventType,
+				Key:        event.TaskID.String(),
+				OccurredAt: event.Payload.OccurredAt,
+				Payload:    payload,
+			})
+			if ctx.Err() != nil {
+				// Сервис останавливается: попытка не засчитывается
+				break
+			}
+			if pubErr != nil {
+				if event.Attempts%outboxLogEvery == 0 {
+					log.Printf("Outbox event %d (%s, task %s) publish failed after %d attempt(s): %v",
+						event.ID, event.EventType, event.TaskID, event.Attempts+1, pubErr)
+				}
+				msg := pubErr.Error()
+				if len(msg) > maxOutboxError {
+					msg = strings.ToValidUTF8(msg[:maxOutboxError], "")
+				}
+				if err := tx.Model(event).Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": msg,
+				}).Error; err != nil {
+					return err
+				}
+				break
+			}
+			ids = append(ids, event.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+		err = tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("published_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		published = len(ids)
+		return nil
+	})
+	return published, err
+}
+
+// PurgePublishedOutbox удаляет события, опубликованные раньше olderThan
+func PurgePublishedOutbox(db *gorm.DB, olderThan time.Time) (int64, error) {
+	result := db.Where("published_at IS NOT NULL AND published_at < ?", olderThan).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// StartOutboxRelay периодически публикует события из outbox, пока не отменён ctx, и затем
+// закрывает publisher. Накопившиеся события публикуются без пауз между пачками.
+// retention = 0 — опубликованные события не удаляются.
+func StartOutboxRelay(ctx context.Context, db *gorm.DB, publisher broker.Publisher, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		defer publisher.Close()
+
+		var lastCleanup time.Time
+
+		for {
+			for ctx.Err() == nil {
+				n, err := RelayOutbox(ctx, db, publisher)
+				if err != nil {
+					log.Printf("Outbox relay run failed: %v", err)
+				}
+				if n < outboxBatchSize {
+					break
+				}
+			}
+
+			if retention > 0 && time.Since(lastCleanup) >= outboxCleanupInterval {
+				lastCleanup = time.Now()
+				if _, err := PurgePublishedOutbox(db, time.Now().Add(-retention)); err != nil {
+					log.Printf("Outbox cleanup failed: %v", err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
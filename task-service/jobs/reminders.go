@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"task-service/models"
+	"task-service/notify"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ключ advisory-блокировки Postgres: при нескольких репликах сроки проверяет только одна
+const reminderLockKey int64 = 0x7461736b72656d // "taskrem"
+
+// Напоминание, опоздавшее больше чем на reminderMaxLag (например, сервис был выключен
+// или задача давно просрочена к моменту запуска), не отправляется
+const reminderMaxLag = 24 * time.Hour
+
+// ReminderKind возвращает вид напоминания для смещения: "24h", "90m" или "overdue"
+func ReminderKind(offset time.Duration) string {
+	switch {
+	case offset <= 0:
+		return models.ReminderOverdue
+	case offset%time.Hour == 0:
+		return fmt.Sprintf("%dh", offset/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", offset/time.Minute)
+	}
+}
+
+// SendDueReminders отправляет напоминания, время которых наступило, и отмечает их в task_reminders.
+// Отметка фиксируется сразу после отправки каждого напоминания, без общей транзакции: сбой
+// посередине не приводит к повторной отправке уже доставленных. Напоминание, которое
+// не удалось доставить, будет отправлено при следующем запуске.
+func SendDueReminders(ctx context.Context, db *gorm.DB, notifier notify.Notifier, offsets []time.Duration) (int, error) {
+	sent := 0
+	// Блокировка занята — значит, сроки проверяет другая реплика
+	_, err := withSessionLock(ctx, db, reminderLockKey, func(conn *gorm.DB) error {
+		now := time.Now()
+		for _, offset := range offsets {
+			kind := ReminderKind(offset)
+			fireBefore := now.Add(offset)
+
+			query := conn.Preload("Assignees").
+				Where("tasks.status IN ?", []models.TaskStatus{models.StatusPending, models.StatusInProgress}).
+				Where("tasks.due_date <= ? AND tasks.due_date > ?", fireBefore, fireBefore.Add(-reminderMaxLag)).
+				Where(`NOT EXISTS (
+					SELECT 1 FROM task_schema.task_reminders r
+					WHERE r.task_id = tasks.id AND r.kind = ? AND r.due_date = tasks.due_date)`, kind)
+			if offset > 0 {
+				// Предварительные напоминания после наступления срока уже не нужны
+				query = query.Where("tasks.due_date > ?", now)
+			}
+
+			var tasks []models.Task
+			if err := query.Order("tasks.due_date").Find(&tasks).Error; err != nil {
+				return err
+			}
+
+			for i := range tasks {
+				if ctx.Err() != nil {
+					return nil
+				}
+				task := &tasks[i]
+				if err := notifier.Notify(ctx, newReminder(task, kind)); err != nil {
+					log.Printf("Reminder %s for task %s failed: %v", kind, task.ID, err)
+					continue
+				}
+
+				// Напоминание уже ушло: отметка пишется, даже если сервис останавливается
+				mark := models.TaskReminder{TaskID: task.ID, Kind: kind, DueDate: *task.DueDate}
+				if err := conn.WithContext(context.Background()).
+					Clauses(clause.OnConflict{DoNothing: true}).
+					Create(&mark).Error; err != nil {
+					return err
+				}
+				sent++
+			}
+		}
+		return nil
+	})
+	return sent, err
+}
+
+func newReminder(task *models.Task, kind string) notify.Reminder {
+	recipients := []uuid.UUID{task.CreatedBy}
+	for _, a := range task.Assignees {
+		if a.UserID != task.CreatedBy {
+			recipients = append(recipients, a.UserID)
+		}
+	}
+
+	return notify.Reminder{
+		Kind:       kind,
+		TaskID:     task.ID,
+		Title:      task.Title,
+		Status:     string(task.Status),
+		Priority:   string(task.Priority),
+		DueDate:    *task.DueDate,
+		Recipients: recipients,
+	}
+}
+
+// StartReminderScheduler периодически проверяет сроки задач, пока не отменён ctx
+func StartReminderScheduler(ctx context.Context, db *gorm.DB, notifier notify.Notifier, offsets []time.Duration, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n, err := SendDueReminders(ctx, db, notifier, offsets)
+			if err != nil {
+				log.Printf("Reminder scan failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Sent %d reminder(s)", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"task-service/models"
+	"task-service/storage"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PurgeTrash окончательно удаляет задачи, которые лежат в корзине дольше retention.
+// Подзадачи удаляются каскадно. В журнал и в outbox пишется событие purged от имени системы (нулевой UUID).
+func PurgeTrash(db *gorm.DB, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+
+	var purged []models.Task
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Delete(&purged).Error
+		if err != nil || len(purged) == 0 {
+			return err
+		}
+
+		events := make([]models.TaskEvent, len(purged))
+		for i, t := range purged {
+			events[i] = models.TaskEvent{
+				TaskID:  t.ID,
+				ActorID: uuid.Nil,
+				Action:  models.TaskEventPurged,
+				Changes: models.FieldChanges{},
+			}
+		}
+		if err := tx.Create(&events).Error; err != nil {
+			return err
+		}
+
+		outbox := make([]models.OutboxEvent, len(events))
+		for i, e := range events {
+			outbox[i] = models.NewOutboxEvent(e.ID, models.DomainTaskPurged, e.TaskID, e.ActorID, nil, e.Changes)
+		}
+		return tx.Create(&outbox).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
+
+// StartTrashPurger периодически очищает корзину и удаляет вложения удалённых задач, пока не отменён ctx
+func StartTrashPurger(ctx context.Context, db *gorm.DB, store storage.Storage, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n, err := PurgeTrash(db, retention)
+			if err != nil {
+				log.Printf("Trash purge failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d task(s) from trash", n)
+			}
+
+			if n, err := PurgeOrphanAttachments(ctx, db, store); err != nil {
+				log.Printf("Attachment cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Removed %d attachment(s) of purged tasks", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
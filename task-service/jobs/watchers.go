@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"task-service/models"
+	"task-service/notify"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ключ advisory-блокировки: очередь уведомлений наблюдателям разбирает одна реплика
+const watcherLockKey int64 = 0x7461736b776174 // "taskwat"
+
+const (
+	// Сколько уведомлений отправляется за один проход
+	watcherBatchSize = 200
+	// После стольких неудачных попыток уведомление остаётся в очереди, но больше не отправляется
+	watcherMaxAttempts = 10
+)
+
+// SendWatcherNotifications доставляет накопившиеся уведомления наблюдателям и удаляет их из очереди.
+// Получатели — наблюдатели задачи на момент отправки, кроме автора изменения. Результат каждой
+// отправки фиксируется сразу, без общей транзакции: сбой посередине не приводит к повторной
+// отправке уже доставленных уведомлений.
+func SendWatcherNotifications(ctx context.Context, db *gorm.DB, notifier notify.Notifier) (int, error) {
+	sent := 0
+	_, err := withSessionLock(ctx, db, watcherLockKey, func(conn *gorm.DB) error {
+		var pending []models.TaskWatchNotification
+		err := conn.Where("attempts < ?", watcherMaxAttempts).
+			Order("created_at, id").
+			Limit(watcherBatchSize).
+			Find(&pending).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range pending {
+			if ctx.Err() != nil {
+				return nil
+			}
+			n := &pending[i]
+			activity, err := newActivity(conn, n)
+			if err != nil {
+				return err
+			}
+
+			// Отправка уже состоялась или не состоится: результат пишется, даже если сервис останавливается
+			store := conn.WithContext(context.Background())
+
+			// Некому сообщать или комментарий уже удалён — уведомление просто снимается с очереди
+			if activity != nil && len(activity.Recipients) > 0 {
+				if err := notifier.NotifyActivity(ctx, *activity); err != nil {
+					log.Printf("Watcher notification %s for task %s failed: %v", n.Kind, n.TaskID, err)
+					msg := err.Error()
+					if err := store.Model(n).Updates(map[string]interface{}{
+						"attempts":   gorm.Expr("attempts + 1"),
+						"last_error": msg,
+					}).Error; err != nil {
+						return err
+					}
+					continue
+				}
+				sent++
+			}
+
+			if err := store.Delete(n).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return sent, err
+}
+
+// newActivity собирает уведомление из записи очереди; nil — отправлять нечего
+func newActivity(db *gorm.DB, n *models.TaskWatchNotification) (*notify.Activity, error) {
+	// Задача могла попасть в корзину после изменения — уведомление всё равно уходит
+	var task models.Task
+	if err := db.Unscoped().Where("id = ?", n.TaskID).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var recipients []uuid.UUID
+	err := db.Model(&models.TaskWatcher{}).
+		Where("task_id = ? AND user_id <> ?", n.TaskID, n.ActorID).
+		Order("created_at, user_id").
+		Pluck("user_id", &recipients).Error
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &notify.Activity{
+		Kind:       n.Kind,
+		TaskID:     task.ID,
+		Title:      task.Title,
+		ActorID:    n.ActorID,
+		At:         n.CreatedAt,
+		Recipients: recipients,
+	}
+
+	if n.Kind == models.WatchCommented {
+		if n.CommentID == nil {
+			return nil, nil
+		}
+		var comment models.TaskComment
+		if err := db.Where("id = ?", *n.CommentID).First(&comment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		activity.CommentID = &comment.ID
+		activity.Comment = comment.Body
+		return activity, nil
+	}
+
+	for _, change := range n.Changes {
+		activity.Old, activity.New = change.Old, change.New
+	}
+	return activity, nil
+}
+
+// StartWatcherNotifier периодически разбирает очередь уведомлений наблюдателям, пока не отменён ctx
+func StartWatcherNotifier(ctx context.Context, db *gorm.DB, notifier notify.Notifier, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n, err := SendWatcherNotifications(ctx, db, notifier)
+			if err != nil {
+				log.Printf("Watcher notification run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Sent %d watcher notification(s)", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
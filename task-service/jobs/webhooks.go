@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"task-service/models"
+	"task-service/webhook"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Сколько доставок берётся за один проход
+	webhookBatchSize = 100
+	// Задержка перед второй попыткой; каждая следующая вдвое дольше, но не больше webhookRetryMax
+	webhookRetryBase = 30 * time.Second
+	webhookRetryMax  = 6 * time.Hour
+	// Длина текста ошибки, сохраняемого в журнале доставок
+	maxDeliveryError = 1000
+	// Запас аренды захваченной доставки сверх таймаута HTTP-запроса
+	webhookClaimMargin = time.Minute
+)
+
+// WebhookRetryDelay — пауза после неудачной попытки номер attempt (с 1)
+func WebhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return delay
+}
+
+// DeliverWebhooks отправляет доставки, время которых наступило. Каждую доставку реплика
+// сначала захватывает короткой транзакцией (SKIP LOCKED), а отправляет уже без транзакции,
+// так что несколько реплик работают параллельно и не отправляют одно и то же дважды.
+func DeliverWebhooks(ctx context.Context, db *gorm.DB, client *webhook.Client, maxAttempts int) (int, error) {
+	var ids []uuid.UUID
+	err := db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+		Where("EXISTS (SELECT 1 FROM task_schema.webhooks w WHERE w.id = webhook_deliveries.webhook_id AND w.active)").
+		Order("next_attempt_at, created_at").
+		Limit(webhookBatchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		ok, err := deliverWebhook(ctx, db, client, id, maxAttempts)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliverWebhook захватывает доставку, отправляет её и записывает итог. Захват переносит
+// next_attempt_at на время аренды — на время отправки доставка пропадает из очереди, и
+// соединение с БД не держится, пока получатель отвечает. Если реплика упала посреди
+// отправки, по окончании аренды доставку возьмёт следующий проход.
+func deliverWebhook(ctx context.Context, db *gorm.DB, client *webhook.Client, id uuid.UUID, maxAttempts int) (bool, error) {
+	// Значение next_attempt_at служит меткой захвата, поэтому округляется до точности колонки
+	claimedUntil := time.Now().Add(client.HTTPClient.Timeout + webhookClaimMargin).Truncate(time.Microsecond)
+
+	var delivery models.WebhookDelivery
+	var hook models.Webhook
+	claimed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.DeliveryPending, time.Now()).
+			First(&delivery).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Доставку уже взяла другая реплика
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ? AND active", delivery.WebhookID).First(&hook).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		if err := tx.Model(&delivery).Update("next_attempt_at", claimedUntil).Error; err != nil {
+			return err
+		}
+		claimed = true
+		return nil
+	})
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	code, sendErr := client.Send(ctx, webhook.Delivery{
+		ID:     delivery.ID.String(),
+		Event:  delivery.Event,
+		URL:    hook.URL,
+		Secret: hook.Secret,
+		Body:   delivery.Payload,
+	})
+
+	// Итог записывается, только если доставка всё ещё захвачена этой репликой:
+	// после окончания аренды её могли взять заново
+	claim := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.DeliveryPending, claimedUntil)
+
+	if ctx.Err() != nil {
+		// Сервис останавливается: попытка не засчитывается, доставка возвращается в очередь
+		return false, claim.Update("next_attempt_at", time.Now()).Error
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":         delivery.Attempts + 1,
+		"last_status_code": nil,
+		"last_error":       nil,
+	}
+	if code != 0 {
+		updates["last_status_code"] = code
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.DeliveryDelivered
+		updates["delivered_at"] = now
+	case delivery.Attempts+1 >= maxAttempts:
+		updates["status"] = models.DeliveryDead
+		log.Printf("Webhook delivery %s to %s is dead after %d attempts: %v", delivery.ID, hook.URL, delivery.Attempts+1, sendErr)
+	default:
+		updates["next_attempt_at"] = now.Add(WebhookRetryDelay(delivery.Attempts + 1))
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		if len(msg) > maxDeliveryError {
+			msg = strings.ToValidUTF8(msg[:maxDeliveryError], "")
+		}
+		updates["last_error"] = msg
+	}
+	result := claim.Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return sendErr == nil && result.RowsAffected > 0, nil
+}
+
+// PurgeDeliveredWebhooks удаляет из журнала доставки, успешно отправленные раньше olderThan
+func PurgeDeliveredWebhooks(db *gorm.DB, olderThan time.Time) (int64, error) {
+	result := db.Where("status = ? AND delivered_at < ?", models.DeliveryDelivered, olderThan).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+// StartWebhookDelivery периодически отправляет вебхуки и чистит журнал, пока не отменён ctx.
+// retention = 0 — журнал не чистится.
+func StartWebhookDelivery(ctx context.Context, db *gorm.DB, client *webhook.Client, maxAttempts int, retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			n, err := DeliverWebhooks(ctx, db, client, maxAttempts)
+			if err != nil {
+				log.Printf("Webhook delivery run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("Delivered %d webhook(s)", n)
+			}
+
+			if retention > 0 {
+				if _, err := PurgeDeliveredWebhooks(db, time.Now().Add(-retention)); err != nil {
+					log.Printf("Webhook log cleanup failed: %v", err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
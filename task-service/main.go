@@ -10,9 +10,16 @@ import (
 	"syscall"
 	"time"
 
+	"task-service/broker"
+	"task-service/clients"
+	"task-service/config"
 	"task-service/database"
 	"task-service/handlers"
+	"task-service/jobs"
 	"task-service/middleware"
+	"task-service/notify"
+	"task-service/storage"
+	"task-service/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -24,6 +31,8 @@ func main() {
 		log.Println("No .env file found")
 	}
 
+	cfg := config.Load()
+
 	// Connect to database
 	db := database.ConnectDB()
 
@@ -32,6 +41,55 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	// Background jobs stop together with the server
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		log.Fatal("Failed to configure attachment storage:", err)
+	}
+
+	if cfg.TrashRetentionDays > 0 && cfg.TrashPurgeIntervalMinutes > 0 {
+		jobs.StartTrashPurger(jobsCtx, db, store,
+			time.Duration(cfg.TrashRetentionDays)*24*time.Hour,
+			time.Duration(cfg.TrashPurgeIntervalMinutes)*time.Minute)
+	}
+
+	authClient := clients.NewAuthClient()
+
+	notifier, err := notify.New(cfg, authClient)
+	if err != nil {
+		log.Fatal("Failed to configure reminder notifiers:", err)
+	}
+
+	if cfg.ReminderIntervalSeconds > 0 && len(cfg.ReminderOffsets) > 0 {
+		jobs.StartReminderScheduler(jobsCtx, db, notifier, cfg.ReminderOffsets,
+			time.Duration(cfg.ReminderIntervalSeconds)*time.Second)
+	}
+
+	if cfg.WatcherNotifyIntervalSeconds > 0 {
+		jobs.StartWatcherNotifier(jobsCtx, db, notifier,
+			time.Duration(cfg.WatcherNotifyIntervalSeconds)*time.Second)
+	}
+
+	if cfg.WebhookIntervalSeconds > 0 {
+		webhookClient := webhook.NewClient(time.Duration(cfg.WebhookTimeoutSeconds)*time.Second, cfg.WebhookAllowPrivateURLs)
+		jobs.StartWebhookDelivery(jobsCtx, db, webhookClient, max(cfg.WebhookMaxAttempts, 1),
+			time.Duration(cfg.WebhookLogRetentionDays)*24*time.Hour,
+			time.Duration(cfg.WebhookIntervalSeconds)*time.Second)
+	}
+
+	if cfg.OutboxIntervalSeconds > 0 {
+		publisher, err := broker.New(cfg)
+		if err != nil {
+			log.Fatal("Failed to configure outbox broker:", err)
+		}
+		jobs.StartOutboxRelay(jobsCtx, db, publisher,
+			time.Duration(cfg.OutboxRetentionHours)*time.Hour,
+			time.Duration(cfg.OutboxIntervalSeconds)*time.Second)
+	}
+
 	// Create router
 	r := gin.Default()
 
@@ -44,8 +102,13 @@ func main() {
 	//	MaxAge:           12 * time.Hour,
 	//}))
 
-	// Create task handler
-	taskHandler := handlers.NewTaskHandler(db)
+	// Create handlers
+	taskHandler := handlers.NewTaskHandler(db, authClient, cfg, store)
+	projectHandler := handlers.NewProjectHandler(db, authClient)
+	labelHandler := handlers.NewLabelHandler(db)
+	calendarHandler := handlers.NewCalendarHandler(db)
+	viewHandler := handlers.NewViewHandler(db, authClient)
+	webhookHandler := handlers.NewWebhookHandler(db)
 
 	// Health check endpoint
 	r.GET("/health", taskHandler.HealthCheck)
@@ -56,10 +119,113 @@ func main() {
 	{
 		tasks.GET("", taskHandler.GetTasks)
 		tasks.POST("", taskHandler.CreateTask)
+		tasks.GET("/search", taskHandler.SearchTasks)
+		tasks.GET("/stats", taskHandler.GetTaskStats)
+		tasks.GET("/dependencies/graph", taskHandler.GetDependencyGraph)
+		tasks.GET("/trash", taskHandler.GetTrash)
+		tasks.POST("/bulk", taskHandler.BulkTasks)
+		tasks.GET("/export", taskHandler.ExportTasks)
+		tasks.GET("/timer", taskHandler.GetCurrentTimer)
+		tasks.GET("/time", taskHandler.GetTimeSummary)
+		tasks.POST("/import", taskHandler.ImportTasks)
 		tasks.GET("/:id", taskHandler.GetTask)
 		tasks.PUT("/:id", taskHandler.UpdateTask)
+		tasks.PATCH("/:id", taskHandler.PatchTask)
 		tasks.DELETE("/:id", taskHandler.DeleteTask)
+		tasks.POST("/:id/restore", taskHandler.RestoreTask)
+		tasks.DELETE("/:id/purge", taskHandler.PurgeTask)
 		tasks.PATCH("/:id/status", taskHandler.UpdateTaskStatus)
+		tasks.POST("/:id/assignees", taskHandler.AssignTask)
+		tasks.DELETE("/:id/assignees/:userId", taskHandler.UnassignTask)
+		tasks.GET("/:id/watchers", taskHandler.GetWatchers)
+		tasks.POST("/:id/watchers", taskHandler.WatchTask)
+		tasks.DELETE("/:id/watchers/:userId", taskHandler.UnwatchTask)
+		tasks.GET("/:id/subtasks", taskHandler.GetSubtasks)
+		tasks.POST("/:id/subtasks", taskHandler.CreateSubtask)
+		tasks.PUT("/:id/subtasks/order", taskHandler.ReorderSubtasks)
+		tasks.GET("/:id/dependencies", taskHandler.GetTaskDependencies)
+		tasks.POST("/:id/dependencies", taskHandler.AddTaskDependency)
+		tasks.DELETE("/:id/dependencies/:blockerId", taskHandler.RemoveTaskDependency)
+		tasks.GET("/:id/history", taskHandler.GetTaskHistory)
+		tasks.GET("/:id/recurrence", taskHandler.GetRecurrence)
+		tasks.PUT("/:id/recurrence", taskHandler.SetRecurrence)
+		tasks.DELETE("/:id/recurrence", taskHandler.DeleteRecurrence)
+		tasks.GET("/:id/attachments", taskHandler.GetAttachments)
+		tasks.POST("/:id/attachments", taskHandler.UploadAttachment)
+		tasks.GET("/:id/attachments/:attachmentId", taskHandler.DownloadAttachment)
+		tasks.DELETE("/:id/attachments/:attachmentId", taskHandler.DeleteAttachment)
+		tasks.POST("/:id/timer/start", taskHandler.StartTimer)
+		tasks.POST("/:id/timer/stop", taskHandler.StopTimer)
+		tasks.GET("/:id/worklogs", taskHandler.GetWorklogs)
+		tasks.POST("/:id/worklogs", taskHandler.CreateWorklog)
+		tasks.DELETE("/:id/worklogs/:worklogId", taskHandler.DeleteWorklog)
+		tasks.GET("/:id/time", taskHandler.GetTaskTime)
+		tasks.GET("/:id/comments", taskHandler.GetComments)
+		tasks.POST("/:id/comments", taskHandler.CreateComment)
+		tasks.PUT("/:id/comments/:commentId", taskHandler.UpdateComment)
+		tasks.DELETE("/:id/comments/:commentId", taskHandler.DeleteComment)
+		tasks.GET("/:id/comments/:commentId/history", taskHandler.GetCommentHistory)
+		tasks.POST("/:id/labels", taskHandler.AttachLabel)
+		tasks.DELETE("/:id/labels/:labelId", taskHandler.DetachLabel)
+	}
+
+	// Label routes (protected)
+	labels := r.Group("/labels")
+	labels.Use(middleware.AuthMiddleware())
+	{
+		labels.GET("", labelHandler.GetLabels)
+		labels.POST("", labelHandler.CreateLabel)
+		labels.PUT("/:id", labelHandler.UpdateLabel)
+		labels.DELETE("/:id", labelHandler.DeleteLabel)
+	}
+
+	// Saved task list views (protected)
+	views := r.Group("/views")
+	views.Use(middleware.AuthMiddleware())
+	{
+		views.GET("", viewHandler.GetViews)
+		views.POST("", viewHandler.CreateView)
+		views.GET("/:id", viewHandler.GetView)
+		views.PUT("/:id", viewHandler.UpdateView)
+		views.DELETE("/:id", viewHandler.DeleteView)
+		views.PUT("/:id/default", viewHandler.SetDefaultView)
+		views.DELETE("/:id/default", viewHandler.ClearDefaultView)
+	}
+
+	// Outbound webhook routes (protected)
+	webhooks := r.Group("/webhooks")
+	webhooks.Use(middleware.AuthMiddleware())
+	{
+		webhooks.GET("", webhookHandler.GetWebhooks)
+		webhooks.POST("", webhookHandler.CreateWebhook)
+		webhooks.GET("/:id", webhookHandler.GetWebhook)
+		webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+		webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverDelivery)
+	}
+
+	// Calendar routes: the feed itself is protected by the secret token in its URL
+	calendar := r.Group("/calendar")
+	{
+		calendar.GET("/:file", calendarHandler.GetCalendar)
+		calendar.GET("/feed", middleware.AuthMiddleware(), calendarHandler.GetFeed)
+		calendar.POST("/feed", middleware.AuthMiddleware(), calendarHandler.RegenerateFeed)
+		calendar.DELETE("/feed", middleware.AuthMiddleware(), calendarHandler.DeleteFeed)
+	}
+
+	// Project routes (protected)
+	projects := r.Group("/projects")
+	projects.Use(middleware.AuthMiddleware())
+	{
+		projects.GET("", projectHandler.GetProjects)
+		projects.POST("", projectHandler.CreateProject)
+		projects.GET("/:id", projectHandler.GetProject)
+		projects.PUT("/:id", projectHandler.UpdateProject)
+		projects.DELETE("/:id", projectHandler.DeleteProject)
+		projects.GET("/:id/members", projectHandler.GetProjectMembers)
+		projects.POST("/:id/members", projectHandler.AddProjectMember)
+		projects.DELETE("/:id/members/:userId", projectHandler.RemoveProjectMember)
 	}
 
 	// Get port from environment
@@ -87,6 +253,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Give outstanding requests 5 seconds to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_schema.idx_tasks_search_vector;
+ALTER TABLE task_schema.tasks DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,12 @@
+-- Полнотекстовый поиск по названию и описанию задачи.
+-- Используется конфигурация 'simple', чтобы одинаково работать с русским и английским текстом.
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON task_schema.tasks USING GIN (search_vector);
+
+COMMENT ON COLUMN task_schema.tasks.search_vector IS 'Generated full-text vector: title (weight A) and description (weight B)';
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS task_schema.task_assignees (
+    task_id     UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL,
+    assigned_by UUID NOT NULL,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, user_id)
+);
+
+-- Для выборки "задачи, назначенные пользователю"
+CREATE INDEX IF NOT EXISTS idx_task_assignees_user_id ON task_schema.task_assignees(user_id);
+
+COMMENT ON TABLE task_schema.task_assignees IS 'Users a task is assigned to (separate from the task creator)';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS task_schema.idx_tasks_project_id;
+ALTER TABLE task_schema.tasks DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS task_schema.project_members;
+DROP TABLE IF EXISTS task_schema.projects;
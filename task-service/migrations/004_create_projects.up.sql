@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS task_schema.projects (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_by  UUID NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS task_schema.project_members (
+    project_id UUID NOT NULL REFERENCES task_schema.projects(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL,
+    role       VARCHAR(20) NOT NULL DEFAULT 'viewer' CHECK (role IN ('owner', 'editor', 'viewer')),
+    added_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON task_schema.project_members(user_id);
+
+-- При удалении проекта задачи остаются у своих авторов
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES task_schema.projects(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON task_schema.tasks(project_id);
+
+COMMENT ON TABLE task_schema.projects IS 'Containers for tasks with their own member list';
+COMMENT ON COLUMN task_schema.project_members.role IS 'Member role: owner, editor, viewer';
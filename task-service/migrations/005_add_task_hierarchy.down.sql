@@ -0,0 +1,5 @@
+ALTER TABLE task_schema.tasks DROP CONSTRAINT IF EXISTS chk_tasks_parent_not_self;
+DROP INDEX IF EXISTS task_schema.idx_tasks_parent_id;
+ALTER TABLE task_schema.tasks
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS parent_id;
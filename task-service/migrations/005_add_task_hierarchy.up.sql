@@ -0,0 +1,12 @@
+-- Иерархия задач: подзадачи удаляются вместе с родителем
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON task_schema.tasks(parent_id, position);
+
+ALTER TABLE task_schema.tasks
+    ADD CONSTRAINT chk_tasks_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+COMMENT ON COLUMN task_schema.tasks.parent_id IS 'Parent task for subtasks (NULL for top-level tasks)';
+COMMENT ON COLUMN task_schema.tasks.position IS 'Order of a subtask among its siblings';
@@ -0,0 +1,13 @@
+-- Связи "blocker блокирует blocked"
+CREATE TABLE IF NOT EXISTS task_schema.task_dependencies (
+    blocker_id UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT chk_task_dependencies_not_self CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked_id ON task_schema.task_dependencies(blocked_id);
+
+COMMENT ON TABLE task_schema.task_dependencies IS 'Blocks / blocked-by links between tasks (must stay acyclic)';
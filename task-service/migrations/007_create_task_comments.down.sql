@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_schema.task_comment_revisions;
+DROP TABLE IF EXISTS task_schema.task_comments;
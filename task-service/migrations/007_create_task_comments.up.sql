@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS task_schema.task_comments (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id    UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    parent_id  UUID REFERENCES task_schema.task_comments(id) ON DELETE CASCADE,
+    author_id  UUID NOT NULL,
+    body       TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    edited_at  TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task_id ON task_schema.task_comments(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_task_comments_parent_id ON task_schema.task_comments(parent_id);
+
+-- Предыдущие версии текста комментария
+CREATE TABLE IF NOT EXISTS task_schema.task_comment_revisions (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id UUID NOT NULL REFERENCES task_schema.task_comments(id) ON DELETE CASCADE,
+    body       TEXT NOT NULL,
+    edited_by  UUID NOT NULL,
+    edited_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comment_revisions_comment_id ON task_schema.task_comment_revisions(comment_id, edited_at);
+
+COMMENT ON TABLE task_schema.task_comments IS 'Task discussion with one level of replies';
+COMMENT ON TABLE task_schema.task_comment_revisions IS 'Previous bodies of edited comments';
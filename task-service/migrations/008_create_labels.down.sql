@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_schema.task_labels;
+DROP TABLE IF EXISTS task_schema.labels;
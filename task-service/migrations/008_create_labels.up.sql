@@ -0,0 +1,26 @@
+-- Метки: личные (project_id IS NULL) или общие для проекта
+CREATE TABLE IF NOT EXISTS task_schema.labels (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       VARCHAR(100) NOT NULL,
+    color      VARCHAR(7) NOT NULL DEFAULT '#808080' CHECK (color ~ '^#[0-9a-fA-F]{6}$'),
+    owner_id   UUID NOT NULL,
+    project_id UUID REFERENCES task_schema.projects(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Имя метки уникально в пределах владельца или проекта (без учёта регистра)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_owner_name
+    ON task_schema.labels(owner_id, lower(name)) WHERE project_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_project_name
+    ON task_schema.labels(project_id, lower(name)) WHERE project_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS task_schema.task_labels (
+    task_id  UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    label_id UUID NOT NULL REFERENCES task_schema.labels(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label_id ON task_schema.task_labels(label_id);
+
+COMMENT ON TABLE task_schema.labels IS 'User-defined labels, personal or shared within a project';
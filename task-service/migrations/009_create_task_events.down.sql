@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_schema.task_events;
+DROP FUNCTION IF EXISTS task_schema.task_events_immutable();
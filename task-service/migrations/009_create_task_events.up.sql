@@ -0,0 +1,26 @@
+-- Журнал изменений задач. Ссылки на tasks нет: история переживает удаление задачи.
+CREATE TABLE IF NOT EXISTS task_schema.task_events (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id    UUID NOT NULL,
+    actor_id   UUID NOT NULL,
+    action     VARCHAR(50) NOT NULL,
+    changes    JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id ON task_schema.task_events(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_task_events_actor_id ON task_schema.task_events(actor_id);
+
+-- События неизменяемы: запрещаем UPDATE и DELETE на уровне БД
+CREATE OR REPLACE FUNCTION task_schema.task_events_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'task_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_task_events_immutable ON task_schema.task_events;
+CREATE TRIGGER trg_task_events_immutable
+    BEFORE UPDATE OR DELETE ON task_schema.task_events
+    FOR EACH ROW EXECUTE FUNCTION task_schema.task_events_immutable();
+
+COMMENT ON TABLE task_schema.task_events IS 'Append-only audit trail of task changes';
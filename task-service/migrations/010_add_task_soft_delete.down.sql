@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_schema.idx_tasks_deleted_at;
+ALTER TABLE task_schema.tasks DROP COLUMN IF EXISTS deleted_at;
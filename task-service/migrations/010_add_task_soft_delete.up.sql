@@ -0,0 +1,9 @@
+-- Мягкое удаление: задача попадает в корзину и окончательно удаляется позже
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Обычные выборки идут по живым задачам, корзина и очистка — по удалённым
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON task_schema.tasks(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN task_schema.tasks.deleted_at IS 'When the task was moved to the trash (NULL for live tasks)';
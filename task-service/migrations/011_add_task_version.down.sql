@@ -0,0 +1 @@
+ALTER TABLE task_schema.tasks DROP COLUMN IF EXISTS version;
@@ -0,0 +1,5 @@
+-- Версия задачи для оптимистичной блокировки (ETag / If-Match)
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN task_schema.tasks.version IS 'Incremented on every update; exposed as the ETag';
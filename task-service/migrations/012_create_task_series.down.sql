@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS task_schema.idx_tasks_series_occurrence;
+ALTER TABLE task_schema.tasks
+    DROP COLUMN IF EXISTS occurrence,
+    DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS task_schema.task_series;
@@ -0,0 +1,26 @@
+-- Серии повторяющихся задач. Серия хранит правило повторения (подмножество RRULE)
+-- и шаблон полей, из которого создаются следующие повторения.
+CREATE TABLE IF NOT EXISTS task_schema.task_series (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rrule       VARCHAR(255) NOT NULL,
+    starts_at   TIMESTAMP NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority    VARCHAR(50) NOT NULL DEFAULT 'medium',
+    project_id  UUID REFERENCES task_schema.projects(id) ON DELETE SET NULL,
+    created_by  UUID NOT NULL,
+    stopped_at  TIMESTAMP,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES task_schema.task_series(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS occurrence INTEGER NOT NULL DEFAULT 0;
+
+-- Одно повторение с каждым номером в серии: защищает от дублей при повторном завершении
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_series_occurrence
+    ON task_schema.tasks(series_id, occurrence) WHERE series_id IS NOT NULL;
+
+COMMENT ON TABLE task_schema.task_series IS 'Recurrence rules and templates for recurring tasks';
+COMMENT ON COLUMN task_schema.tasks.occurrence IS '1-based number of the occurrence within its series';
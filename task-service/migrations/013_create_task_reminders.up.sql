@@ -0,0 +1,11 @@
+-- Отправленные напоминания о сроке. Ключ включает due_date: после переноса срока
+-- напоминания отправляются заново.
+CREATE TABLE IF NOT EXISTS task_schema.task_reminders (
+    task_id  UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    kind     VARCHAR(32) NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    sent_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, kind, due_date)
+);
+
+COMMENT ON TABLE task_schema.task_reminders IS 'Due-date reminders already sent, one row per task, offset and due date';
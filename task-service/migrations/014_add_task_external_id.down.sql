@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS task_schema.idx_tasks_created_by_external_id;
+ALTER TABLE task_schema.tasks
+    DROP COLUMN IF EXISTS external_id;
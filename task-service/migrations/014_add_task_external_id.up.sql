@@ -0,0 +1,7 @@
+-- Идентификатор задачи во внешней системе (импорт). Уникален среди задач одного автора,
+-- включая задачи в корзине, чтобы повторный импорт не создавал дубли.
+ALTER TABLE task_schema.tasks
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_created_by_external_id
+    ON task_schema.tasks(created_by, external_id) WHERE external_id IS NOT NULL;
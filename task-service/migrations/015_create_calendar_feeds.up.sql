@@ -0,0 +1,9 @@
+-- Секретные ссылки на календарь сроков. Хранится только SHA-256 токена:
+-- сам токен показывается пользователю один раз при создании.
+CREATE TABLE IF NOT EXISTS task_schema.calendar_feeds (
+    user_id    UUID PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE task_schema.calendar_feeds IS 'Per-user secret tokens for the iCalendar feed of task due dates';
@@ -0,0 +1,19 @@
+-- Метаданные вложений задач; содержимое файлов лежит во внешнем хранилище по storage_key.
+-- При окончательном удалении задачи task_id обнуляется, а фоновая очистка удаляет
+-- такие вложения вместе с файлами.
+CREATE TABLE IF NOT EXISTS task_schema.task_attachments (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id         UUID REFERENCES task_schema.tasks(id) ON DELETE SET NULL,
+    uploaded_by     UUID NOT NULL,
+    file_name       VARCHAR(255) NOT NULL,
+    content_type    VARCHAR(255) NOT NULL,
+    size_bytes      BIGINT NOT NULL CHECK (size_bytes >= 0),
+    checksum_sha256 VARCHAR(64) NOT NULL,
+    storage_key     VARCHAR(512) NOT NULL UNIQUE,
+    created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_attachments_task_id ON task_schema.task_attachments(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_task_attachments_uploaded_by ON task_schema.task_attachments(uploaded_by);
+
+COMMENT ON TABLE task_schema.task_attachments IS 'Files attached to tasks; contents are kept in the attachment storage';
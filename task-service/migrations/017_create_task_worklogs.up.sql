@@ -0,0 +1,23 @@
+-- Учёт времени по задачам: записи таймера и ручные записи.
+-- У запущенного таймера ended_at и duration_seconds пусты.
+CREATE TABLE IF NOT EXISTS task_schema.task_worklogs (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id          UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    user_id          UUID NOT NULL,
+    source           VARCHAR(16) NOT NULL CHECK (source IN ('timer', 'manual')),
+    started_at       TIMESTAMP NOT NULL,
+    ended_at         TIMESTAMP,
+    duration_seconds BIGINT CHECK (duration_seconds >= 0),
+    note             TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((ended_at IS NULL) = (duration_seconds IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_worklogs_task_id ON task_schema.task_worklogs(task_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_task_worklogs_user_id ON task_schema.task_worklogs(user_id, started_at);
+
+-- У пользователя может быть запущен только один таймер
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_worklogs_running ON task_schema.task_worklogs(user_id) WHERE ended_at IS NULL;
+
+COMMENT ON TABLE task_schema.task_worklogs IS 'Time spent on tasks: timer runs and manual entries';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_schema.task_view_defaults;
+DROP TABLE IF EXISTS task_schema.task_view_shares;
+DROP TABLE IF EXISTS task_schema.task_views;
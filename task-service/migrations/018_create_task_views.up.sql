@@ -0,0 +1,33 @@
+-- Сохранённые представления списка задач: фильтры, сортировка и колонки под именем
+CREATE TABLE IF NOT EXISTS task_schema.task_views (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id   UUID NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    filters    JSONB NOT NULL DEFAULT '{}'::jsonb,
+    columns    JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Имя представления уникально у владельца (без учёта регистра)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_views_owner_name ON task_schema.task_views(owner_id, lower(name));
+
+-- Пользователи, которым владелец открыл представление
+CREATE TABLE IF NOT EXISTS task_schema.task_view_shares (
+    view_id    UUID NOT NULL REFERENCES task_schema.task_views(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (view_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_view_shares_user_id ON task_schema.task_view_shares(user_id);
+
+-- Представление по умолчанию: своё или открытое пользователю
+CREATE TABLE IF NOT EXISTS task_schema.task_view_defaults (
+    user_id UUID PRIMARY KEY,
+    view_id UUID NOT NULL REFERENCES task_schema.task_views(id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE task_schema.task_views IS 'Named task list filters, sort order and columns saved per user';
+COMMENT ON TABLE task_schema.task_view_shares IS 'Users a saved view is shared with';
+COMMENT ON TABLE task_schema.task_view_defaults IS 'Saved view applied to GET /tasks when no filters are given';
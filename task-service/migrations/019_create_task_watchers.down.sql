@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS task_schema.task_watch_notifications;
+DROP TRIGGER IF EXISTS trg_task_assignees_watch ON task_schema.task_assignees;
+DROP TRIGGER IF EXISTS trg_tasks_watch_creator ON task_schema.tasks;
+DROP FUNCTION IF EXISTS task_schema.task_watchers_subscribe();
+DROP TABLE IF EXISTS task_schema.task_watchers;
@@ -0,0 +1,62 @@
+-- Наблюдатели задачи получают уведомления о её изменениях и право на чтение
+CREATE TABLE IF NOT EXISTS task_schema.task_watchers (
+    task_id    UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (task_id, user_id)
+);
+
+-- Для проверки доступа и выборки "задачи, за которыми я слежу"
+CREATE INDEX IF NOT EXISTS idx_task_watchers_user_id ON task_schema.task_watchers(user_id);
+
+COMMENT ON TABLE task_schema.task_watchers IS 'Users subscribed to task change notifications; watchers can read the task';
+
+-- Авторы и исполнители существующих задач становятся наблюдателями
+INSERT INTO task_schema.task_watchers (task_id, user_id)
+SELECT id, created_by FROM task_schema.tasks
+UNION
+SELECT task_id, user_id FROM task_schema.task_assignees
+ON CONFLICT DO NOTHING;
+
+-- Автор и исполнители подписываются автоматически, каким бы путём ни была создана
+-- задача или назначение (API, пакетные операции, импорт, повторения)
+CREATE OR REPLACE FUNCTION task_schema.task_watchers_subscribe() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'tasks' THEN
+        INSERT INTO task_schema.task_watchers (task_id, user_id)
+        VALUES (NEW.id, NEW.created_by) ON CONFLICT DO NOTHING;
+    ELSE
+        INSERT INTO task_schema.task_watchers (task_id, user_id)
+        VALUES (NEW.task_id, NEW.user_id) ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_tasks_watch_creator ON task_schema.tasks;
+CREATE TRIGGER trg_tasks_watch_creator
+    AFTER INSERT ON task_schema.tasks
+    FOR EACH ROW EXECUTE FUNCTION task_schema.task_watchers_subscribe();
+
+DROP TRIGGER IF EXISTS trg_task_assignees_watch ON task_schema.task_assignees;
+CREATE TRIGGER trg_task_assignees_watch
+    AFTER INSERT ON task_schema.task_assignees
+    FOR EACH ROW EXECUTE FUNCTION task_schema.task_watchers_subscribe();
+
+-- Очередь уведомлений наблюдателям. Запись добавляется в той же транзакции, что и изменение
+-- задачи, и удаляется после доставки; получатели определяются в момент отправки.
+CREATE TABLE IF NOT EXISTS task_schema.task_watch_notifications (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id    UUID NOT NULL REFERENCES task_schema.tasks(id) ON DELETE CASCADE,
+    actor_id   UUID NOT NULL,
+    kind       VARCHAR(32) NOT NULL,
+    changes    JSONB NOT NULL DEFAULT '{}'::jsonb,
+    comment_id UUID,
+    attempts   INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_watch_notifications_created_at ON task_schema.task_watch_notifications(created_at);
+
+COMMENT ON TABLE task_schema.task_watch_notifications IS 'Pending watcher notifications about status, due date and comment changes';
@@ -0,0 +1,41 @@
+-- Исходящие вебхуки. Секрет хранится открыто: им подписывается каждая доставка.
+-- all_tasks (задаётся только администратором) — события по всем задачам, иначе только по видимым владельцу.
+CREATE TABLE IF NOT EXISTS task_schema.webhooks (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id   UUID NOT NULL,
+    url        TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    events     JSONB NOT NULL DEFAULT '[]'::jsonb,
+    all_tasks  BOOLEAN NOT NULL DEFAULT FALSE,
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_owner_id ON task_schema.webhooks(owner_id);
+
+COMMENT ON TABLE task_schema.webhooks IS 'Outbound webhook subscriptions to task events';
+
+-- Доставки вебхуков: добавляются в транзакции изменения задачи, отправляются фоновой задачей.
+-- Ссылки на tasks нет: событие об удалении доставляется и после очистки корзины.
+CREATE TABLE IF NOT EXISTS task_schema.webhook_deliveries (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id       UUID NOT NULL REFERENCES task_schema.webhooks(id) ON DELETE CASCADE,
+    event_id         UUID NOT NULL,
+    event            VARCHAR(50) NOT NULL,
+    task_id          UUID NOT NULL,
+    payload          JSONB NOT NULL,
+    status           VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts         INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error       TEXT,
+    delivered_at     TIMESTAMP,
+    created_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at       TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON task_schema.webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON task_schema.webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+
+COMMENT ON TABLE task_schema.webhook_deliveries IS 'Webhook delivery log: pending, delivered and dead-lettered deliveries';
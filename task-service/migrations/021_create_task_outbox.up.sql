@@ -0,0 +1,20 @@
+-- Transactional outbox: доменные события задач пишутся в той же транзакции, что и изменение задачи,
+-- а фоновый relay публикует их в брокер. Порядок публикации — по id; события одной задачи
+-- получают возрастающие id, потому что изменения задачи выполняются под блокировкой её строки.
+-- Ссылки на tasks нет: событие об окончательном удалении публикуется уже после удаления задачи.
+CREATE TABLE IF NOT EXISTS task_schema.task_outbox (
+    id           BIGSERIAL PRIMARY KEY,
+    event_id     UUID NOT NULL UNIQUE,
+    task_id      UUID NOT NULL,
+    event_type   VARCHAR(50) NOT NULL,
+    payload      JSONB NOT NULL,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    published_at TIMESTAMP,
+    created_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_outbox_unpublished ON task_schema.task_outbox(id) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_task_outbox_published_at ON task_schema.task_outbox(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE task_schema.task_outbox IS 'Transactional outbox of task domain events awaiting publication to the message broker';
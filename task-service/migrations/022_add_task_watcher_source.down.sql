@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS trg_task_assignees_unwatch ON task_schema.task_assignees;
+DROP FUNCTION IF EXISTS task_schema.task_watchers_unsubscribe_assignee();
+
+CREATE OR REPLACE FUNCTION task_schema.task_watchers_subscribe() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'tasks' THEN
+        INSERT INTO task_schema.task_watchers (task_id, user_id)
+        VALUES (NEW.id, NEW.created_by) ON CONFLICT DO NOTHING;
+    ELSE
+        INSERT INTO task_schema.task_watchers (task_id, user_id)
+        VALUES (NEW.task_id, NEW.user_id) ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE task_schema.task_watchers DROP COLUMN IF EXISTS source;
@@ -0,0 +1,44 @@
+-- Откуда взялась подписка: manual — пользователь подписался сам или его подписали,
+-- creator и assignee — автоматическая подписка автора и исполнителя.
+-- Автоматическая подписка исполнителя снимается вместе с назначением.
+ALTER TABLE task_schema.task_watchers
+    ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'manual'
+        CHECK (source IN ('manual', 'creator', 'assignee'));
+
+UPDATE task_schema.task_watchers w
+SET source = 'creator'
+FROM task_schema.tasks t
+WHERE t.id = w.task_id AND t.created_by = w.user_id;
+
+UPDATE task_schema.task_watchers w
+SET source = 'assignee'
+FROM task_schema.task_assignees a
+WHERE a.task_id = w.task_id AND a.user_id = w.user_id AND w.source = 'manual';
+
+CREATE OR REPLACE FUNCTION task_schema.task_watchers_subscribe() RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'tasks' THEN
+        INSERT INTO task_schema.task_watchers (task_id, user_id, source)
+        VALUES (NEW.id, NEW.created_by, 'creator') ON CONFLICT DO NOTHING;
+    ELSE
+        INSERT INTO task_schema.task_watchers (task_id, user_id, source)
+        VALUES (NEW.task_id, NEW.user_id, 'assignee') ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Снятие исполнителя удаляет только его автоматическую подписку: подписку,
+-- оформленную вручную, и подписку автора задачи оно не трогает
+CREATE OR REPLACE FUNCTION task_schema.task_watchers_unsubscribe_assignee() RETURNS trigger AS $$
+BEGIN
+    DELETE FROM task_schema.task_watchers
+    WHERE task_id = OLD.task_id AND user_id = OLD.user_id AND source = 'assignee';
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_task_assignees_unwatch ON task_schema.task_assignees;
+CREATE TRIGGER trg_task_assignees_unwatch
+    AFTER DELETE ON task_schema.task_assignees
+    FOR EACH ROW EXECUTE FUNCTION task_schema.task_watchers_unsubscribe_assignee();
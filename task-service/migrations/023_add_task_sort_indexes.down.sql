@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS task_schema.idx_tasks_sort_priority;
+DROP INDEX IF EXISTS task_schema.idx_tasks_sort_due_date;
+DROP INDEX IF EXISTS task_schema.idx_tasks_sort_created_at;
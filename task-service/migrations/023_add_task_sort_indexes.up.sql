@@ -0,0 +1,11 @@
+-- Индексы для сортировки и курсорной пагинации GET /tasks. Выражения должны в точности
+-- совпадать с taskSortExpressions (handlers/task_query.go), иначе планировщик их не использует.
+-- Вторая колонка — id, по которому упорядочиваются задачи с одинаковым значением.
+CREATE INDEX IF NOT EXISTS idx_tasks_sort_created_at ON task_schema.tasks(created_at, id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_sort_due_date ON task_schema.tasks((COALESCE(due_date, 'infinity'::timestamp)), id)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_sort_priority ON task_schema.tasks((CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 ELSE 0 END), id)
+    WHERE deleted_at IS NULL;
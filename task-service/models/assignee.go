@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskAssignee struct {
+	TaskID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	AssignedBy uuid.UUID `gorm:"type:uuid;not null" json:"assigned_by"`
+	AssignedAt time.Time `gorm:"autoCreateTime" json:"assigned_at"`
+}
+
+func (TaskAssignee) TableName() string {
+	return "task_schema.task_assignees"
+}
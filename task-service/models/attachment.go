@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskAttachment — метаданные файла, прикреплённого к задаче.
+// TaskID пуст, если задача удалена окончательно и файл ждёт очистки.
+type TaskAttachment struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	TaskID         *uuid.UUID `gorm:"type:uuid" json:"task_id"`
+	UploadedBy     uuid.UUID  `gorm:"type:uuid;not null" json:"uploaded_by"`
+	FileName       string     `gorm:"not null" json:"file_name"`
+	ContentType    string     `gorm:"not null" json:"content_type"`
+	SizeBytes      int64      `gorm:"not null" json:"size_bytes"`
+	ChecksumSHA256 string     `gorm:"column:checksum_sha256;not null" json:"checksum_sha256"`
+	StorageKey     string     `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (a *TaskAttachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskAttachment) TableName() string {
+	return "task_schema.task_attachments"
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed — секретная ссылка пользователя на iCalendar-ленту; хранится хеш токена
+type CalendarFeed struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	TokenHash string    `gorm:"not null;uniqueIndex" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (CalendarFeed) TableName() string {
+	return "task_schema.calendar_feeds"
+}
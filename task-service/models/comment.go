@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskComment struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	TaskID    uuid.UUID  `gorm:"type:uuid;not null" json:"task_id"`
+	ParentID  *uuid.UUID `gorm:"type:uuid" json:"parent_id,omitempty"`
+	AuthorID  uuid.UUID  `gorm:"type:uuid;not null" json:"author_id"`
+	Body      string     `gorm:"not null" json:"body"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+
+	Replies []TaskComment `gorm:"foreignKey:ParentID" json:"replies,omitempty"`
+}
+
+func (c *TaskComment) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskComment) TableName() string {
+	return "task_schema.task_comments"
+}
+
+// TaskCommentRevision хранит текст комментария до очередного редактирования
+type TaskCommentRevision struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	CommentID uuid.UUID `gorm:"type:uuid;not null" json:"comment_id"`
+	Body      string    `gorm:"not null" json:"body"`
+	EditedBy  uuid.UUID `gorm:"type:uuid;not null" json:"edited_by"`
+	EditedAt  time.Time `gorm:"autoCreateTime" json:"edited_at"`
+}
+
+func (r *TaskCommentRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskCommentRevision) TableName() string {
+	return "task_schema.task_comment_revisions"
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency — задача BlockerID блокирует задачу BlockedID
+type TaskDependency struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey" json:"blocker_id"`
+	BlockedID uuid.UUID `gorm:"type:uuid;primaryKey" json:"blocked_id"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TaskDependency) TableName() string {
+	return "task_schema.task_dependencies"
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskEventAction string
+
+const (
+	TaskEventCreated       TaskEventAction = "created"
+	TaskEventUpdated       TaskEventAction = "updated"
+	TaskEventStatusChanged TaskEventAction = "status_changed"
+	TaskEventDeleted       TaskEventAction = "deleted"
+	TaskEventRestored      TaskEventAction = "restored"
+	TaskEventPurged        TaskEventAction = "purged"
+	TaskEventAssigned      TaskEventAction = "assigned"
+	TaskEventUnassigned    TaskEventAction = "unassigned"
+)
+
+// FieldChange — значение поля до и после изменения (nil — поле не было задано)
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// FieldChanges хранится в JSONB-колонке changes
+type FieldChanges map[string]FieldChange
+
+func (f FieldChanges) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (f *FieldChanges) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*f = FieldChanges{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	}
+	return errors.New("unsupported type for FieldChanges")
+}
+
+// TaskEvent — неизменяемая запись журнала изменений задачи
+type TaskEvent struct {
+	ID        uuid.UUID       `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	TaskID    uuid.UUID       `gorm:"type:uuid;not null" json:"task_id"`
+	ActorID   uuid.UUID       `gorm:"type:uuid;not null" json:"actor_id"`
+	Action    TaskEventAction `gorm:"not null" json:"action"`
+	Changes   FieldChanges    `gorm:"type:jsonb;not null" json:"changes"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (e *TaskEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskEvent) TableName() string {
+	return "task_schema.task_events"
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func IsValidLabelColor(color string) bool {
+	return labelColorPattern.MatchString(color)
+}
+
+// Label — личная метка пользователя (ProjectID == nil) или общая метка проекта
+type Label struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	Name      string     `gorm:"not null" json:"name"`
+	Color     string     `gorm:"default:'#808080'" json:"color"`
+	OwnerID   uuid.UUID  `gorm:"type:uuid;not null" json:"owner_id"`
+	ProjectID *uuid.UUID `gorm:"type:uuid" json:"project_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (l *Label) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	if l.Color == "" {
+		l.Color = "#808080"
+	}
+	return nil
+}
+
+func (Label) TableName() string {
+	return "task_schema.labels"
+}
+
+type TaskLabel struct {
+	TaskID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LabelID uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
+
+func (TaskLabel) TableName() string {
+	return "task_schema.task_labels"
+}
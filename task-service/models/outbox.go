@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Доменные события задач, которые публикуются в брокер для других сервисов
+const (
+	DomainTaskCreated       = "task.created"
+	DomainTaskUpdated       = "task.updated"
+	DomainTaskStatusChanged = "task.status_changed"
+	DomainTaskDeleted       = "task.deleted"
+	DomainTaskRestored      = "task.restored"
+	DomainTaskPurged        = "task.purged"
+	DomainTaskAssigned      = "task.assigned"
+	DomainTaskUnassigned    = "task.unassigned"
+)
+
+var domainEventTypes = map[TaskEventAction]string{
+	TaskEventCreated:       DomainTaskCreated,
+	TaskEventUpdated:       DomainTaskUpdated,
+	TaskEventStatusChanged: DomainTaskStatusChanged,
+	TaskEventDeleted:       DomainTaskDeleted,
+	TaskEventRestored:      DomainTaskRestored,
+	TaskEventPurged:        DomainTaskPurged,
+	TaskEventAssigned:      DomainTaskAssigned,
+	TaskEventUnassigned:    DomainTaskUnassigned,
+}
+
+// DomainEventType — доменное событие для действия журнала изменений
+func DomainEventType(action TaskEventAction) string {
+	return domainEventTypes[action]
+}
+
+// TaskDomainEvent — тело доменного события (JSONB). Task — состояние задачи после изменения
+// вместе с исполнителями (nil для task.purged); UserIDs — кого назначили или сняли.
+type TaskDomainEvent struct {
+	ID         uuid.UUID    `json:"id"`
+	Type       string       `json:"type"`
+	TaskID     uuid.UUID    `json:"task_id"`
+	ActorID    uuid.UUID    `json:"actor_id"`
+	OccurredAt time.Time    `json:"occurred_at"`
+	Task       *Task        `json:"task"`
+	Changes    FieldChanges `json:"changes"`
+	UserIDs    []uuid.UUID  `json:"user_ids,omitempty"`
+}
+
+func (e TaskDomainEvent) Value() (driver.Value, error) {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (e *TaskDomainEvent) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	}
+	return errors.New("unsupported type for TaskDomainEvent")
+}
+
+// OutboxEvent — доменное событие, ожидающее публикации. ID задаёт порядок публикации.
+type OutboxEvent struct {
+	ID          int64           `gorm:"primary_key" json:"id"`
+	EventID     uuid.UUID       `gorm:"type:uuid;not null" json:"event_id"`
+	TaskID      uuid.UUID       `gorm:"type:uuid;not null" json:"task_id"`
+	EventType   string          `gorm:"not null" json:"event_type"`
+	Payload     TaskDomainEvent `gorm:"type:jsonb;not null" json:"payload"`
+	Attempts    int             `gorm:"not null;default:0" json:"attempts"`
+	LastError   *string         `json:"last_error"`
+	PublishedAt *time.Time      `json:"published_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// NewOutboxEvent собирает запись outbox для события; eventID — ключ дедупликации у получателей
+func NewOutboxEvent(eventID uuid.UUID, eventType string, taskID, actorID uuid.UUID, task *Task, changes FieldChanges) OutboxEvent {
+	if changes == nil {
+		changes = FieldChanges{}
+	}
+	return OutboxEvent{
+		EventID:   eventID,
+		TaskID:    taskID,
+		EventType: eventType,
+		Payload: TaskDomainEvent{
+			ID:         eventID,
+			Type:       eventType,
+			TaskID:     taskID,
+			ActorID:    actorID,
+			OccurredAt: time.Now().UTC(),
+			Task:       task,
+			Changes:    changes,
+		},
+	}
+}
+
+func (OutboxEvent) TableName() string {
+	return "task_schema.task_outbox"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProjectRole string
+
+const (
+	ProjectRoleOwner  ProjectRole = "owner"
+	ProjectRoleEditor ProjectRole = "editor"
+	ProjectRoleViewer ProjectRole = "viewer"
+)
+
+func IsValidProjectRole(r string) bool {
+	switch ProjectRole(r) {
+	case ProjectRoleOwner, ProjectRoleEditor, ProjectRoleViewer:
+		return true
+	}
+	return false
+}
+
+// CanEdit — может ли участник с этой ролью изменять задачи проекта
+func (r ProjectRole) CanEdit() bool {
+	return r == ProjectRoleOwner || r == ProjectRoleEditor
+}
+
+type Project struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `json:"description"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Members []ProjectMember `gorm:"foreignKey:ProjectID" json:"members,omitempty"`
+}
+
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Project) TableName() string {
+	return "task_schema.projects"
+}
+
+type ProjectMember struct {
+	ProjectID uuid.UUID   `gorm:"type:uuid;primaryKey" json:"project_id"`
+	UserID    uuid.UUID   `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role      ProjectRole `gorm:"not null" json:"role"`
+	AddedAt   time.Time   `gorm:"autoCreateTime" json:"added_at"`
+}
+
+func (ProjectMember) TableName() string {
+	return "task_schema.project_members"
+}
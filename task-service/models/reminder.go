@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReminderOverdue — вид напоминания, которое отправляется, когда срок уже прошёл
+const ReminderOverdue = "overdue"
+
+// TaskReminder отмечает напоминание, уже отправленное для задачи с данным сроком
+type TaskReminder struct {
+	TaskID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"task_id"`
+	Kind    string    `gorm:"primaryKey" json:"kind"`
+	DueDate time.Time `gorm:"primaryKey" json:"due_date"`
+	SentAt  time.Time `gorm:"autoCreateTime" json:"sent_at"`
+}
+
+func (TaskReminder) TableName() string {
+	return "task_schema.task_reminders"
+}
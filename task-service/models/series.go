@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskSeries — серия повторяющихся задач. Title, Description, Priority и ProjectID —
+// шаблон для следующих повторений; StartsAt — срок первого повторения (DTSTART).
+type TaskSeries struct {
+	ID          uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	RRule       string       `gorm:"column:rrule;not null" json:"rrule"`
+	StartsAt    time.Time    `gorm:"not null" json:"starts_at"`
+	Title       string       `gorm:"not null" json:"title"`
+	Description string       `json:"description"`
+	Priority    TaskPriority `gorm:"default:'medium'" json:"priority"`
+	ProjectID   *uuid.UUID   `gorm:"type:uuid" json:"project_id,omitempty"`
+	CreatedBy   uuid.UUID    `gorm:"type:uuid;not null" json:"created_by"`
+	StoppedAt   *time.Time   `json:"stopped_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func (s *TaskSeries) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskSeries) TableName() string {
+	return "task_schema.task_series"
+}
@@ -22,6 +22,64 @@ const (
 	PriorityUrgent TaskPriority = "urgent"
 )
 
+func IsValidStatus(s string) bool {
+	switch TaskStatus(s) {
+	case StatusPending, StatusInProgress, StatusCompleted, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+func IsValidPriority(p string) bool {
+	switch TaskPriority(p) {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// statusTransitions — допустимые переходы статуса. Завершённую или отменённую задачу
+// можно переоткрыть; отменить можно только незавершённую.
+var statusTransitions = map[TaskStatus][]TaskStatus{
+	StatusPending:    {StatusInProgress, StatusCancelled},
+	StatusInProgress: {StatusPending, StatusCompleted, StatusCancelled},
+	StatusCompleted:  {StatusInProgress},
+	StatusCancelled:  {StatusPending},
+}
+
+// AllowedTransitions возвращает статусы, в которые можно перейти из from
+func AllowedTransitions(from TaskStatus) []TaskStatus {
+	return statusTransitions[from]
+}
+
+// CanTransition сообщает, разрешён ли переход; повторная установка того же статуса допустима
+func CanTransition(from, to TaskStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// PriorityWeight возвращает вес приоритета для сортировки (low < medium < high < urgent)
+func PriorityWeight(p TaskPriority) int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	case PriorityUrgent:
+		return 4
+	}
+	return 0
+}
+
 type Task struct {
 	ID          uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
 	Title       string       `gorm:"not null" json:"title" binding:"required"`
@@ -29,9 +87,30 @@ type Task struct {
 	Status      TaskStatus   `gorm:"default:'pending'" json:"status"`
 	Priority    TaskPriority `gorm:"default:'medium'" json:"priority"`
 	DueDate     *time.Time   `json:"due_date,omitempty"`
+	ProjectID   *uuid.UUID   `gorm:"type:uuid" json:"project_id,omitempty"`
+	ParentID    *uuid.UUID   `gorm:"type:uuid" json:"parent_id,omitempty"`
+	Position    int          `gorm:"not null;default:0" json:"position"`
+	Version     int          `gorm:"not null;default:1" json:"version"`
+	SeriesID    *uuid.UUID   `gorm:"type:uuid" json:"series_id,omitempty"`
+	Occurrence  int          `gorm:"not null;default:0" json:"occurrence,omitempty"`
+	ExternalID  *string      `gorm:"size:255" json:"external_id,omitempty"`
 	CreatedBy   uuid.UUID    `gorm:"type:uuid;not null" json:"created_by"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
+	// Задачи с DeletedAt лежат в корзине; GORM исключает их из обычных запросов
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+
+	Assignees []TaskAssignee `gorm:"foreignKey:TaskID" json:"assignees,omitempty"`
+	Labels    []Label        `gorm:"-" json:"labels,omitempty"`
+	Progress  *TaskProgress  `gorm:"-" json:"progress,omitempty"`
+}
+
+// TaskProgress — сводка по прямым подзадачам. Отменённые подзадачи не учитываются в проценте.
+type TaskProgress struct {
+	TotalChildren     int64   `json:"total_children"`
+	CompletedChildren int64   `json:"completed_children"`
+	OpenChildren      int64   `json:"open_children"`
+	PercentComplete   float64 `json:"percent_complete"`
 }
 
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ViewFilters — параметры запроса GET /tasks, сохранённые в представлении (JSONB)
+type ViewFilters map[string]string
+
+func (f ViewFilters) Value() (driver.Value, error) {
+	if f == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (f *ViewFilters) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*f = ViewFilters{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	}
+	return errors.New("unsupported type for ViewFilters")
+}
+
+// ViewColumns — колонки списка в порядке отображения (JSONB)
+type ViewColumns []string
+
+func (c ViewColumns) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (c *ViewColumns) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c = ViewColumns{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return errors.New("unsupported type for ViewColumns")
+}
+
+// TaskView — сохранённое представление списка задач
+type TaskView struct {
+	ID        uuid.UUID   `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	OwnerID   uuid.UUID   `gorm:"type:uuid;not null" json:"owner_id"`
+	Name      string      `gorm:"not null" json:"name"`
+	Filters   ViewFilters `gorm:"type:jsonb;not null" json:"filters"`
+	Columns   ViewColumns `gorm:"type:jsonb;not null" json:"columns"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+
+	// SharedWith заполняется только для владельца
+	SharedWith []uuid.UUID `gorm:"-" json:"shared_with,omitempty"`
+	IsDefault  bool        `gorm:"-" json:"is_default"`
+}
+
+func (v *TaskView) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskView) TableName() string {
+	return "task_schema.task_views"
+}
+
+type TaskViewShare struct {
+	ViewID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"view_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (TaskViewShare) TableName() string {
+	return "task_schema.task_view_shares"
+}
+
+type TaskViewDefault struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	ViewID uuid.UUID `gorm:"type:uuid;not null" json:"view_id"`
+}
+
+func (TaskViewDefault) TableName() string {
+	return "task_schema.task_view_defaults"
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Виды уведомлений наблюдателям
+const (
+	WatchStatusChanged  = "status_changed"
+	WatchDueDateChanged = "due_date_changed"
+	WatchCommented      = "commented"
+)
+
+// Происхождение подписки: автоматические подписки создаются триггерами,
+// подписка исполнителя снимается вместе с назначением
+const (
+	WatchSourceManual   = "manual"
+	WatchSourceCreator  = "creator"
+	WatchSourceAssignee = "assignee"
+)
+
+// TaskWatcher — подписка пользователя на изменения задачи
+type TaskWatcher struct {
+	TaskID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Source    string    `gorm:"not null;default:manual" json:"source"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (TaskWatcher) TableName() string {
+	return "task_schema.task_watchers"
+}
+
+// TaskWatchNotification — ещё не доставленное уведомление наблюдателям задачи
+type TaskWatchNotification struct {
+	ID        uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primary_key"`
+	TaskID    uuid.UUID    `gorm:"type:uuid;not null"`
+	ActorID   uuid.UUID    `gorm:"type:uuid;not null"`
+	Kind      string       `gorm:"not null"`
+	Changes   FieldChanges `gorm:"type:jsonb;not null"`
+	CommentID *uuid.UUID   `gorm:"type:uuid"`
+	Attempts  int          `gorm:"not null;default:0"`
+	LastError *string
+	CreatedAt time.Time
+}
+
+func (TaskWatchNotification) TableName() string {
+	return "task_schema.task_watch_notifications"
+}
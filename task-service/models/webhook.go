@@ -0,0 +1,166 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// События задач, на которые можно подписать вебхук
+const (
+	WebhookTaskCreated       = "task.created"
+	WebhookTaskUpdated       = "task.updated"
+	WebhookTaskStatusChanged = "task.status_changed"
+	WebhookTaskDeleted       = "task.deleted"
+)
+
+var webhookEvents = map[string]bool{
+	WebhookTaskCreated:       true,
+	WebhookTaskUpdated:       true,
+	WebhookTaskStatusChanged: true,
+	WebhookTaskDeleted:       true,
+}
+
+func IsValidWebhookEvent(event string) bool {
+	return webhookEvents[event]
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// Все попытки исчерпаны; доставку можно повторить вручную
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+func IsValidDeliveryStatus(status string) bool {
+	switch DeliveryStatus(status) {
+	case DeliveryPending, DeliveryDelivered, DeliveryDead:
+		return true
+	}
+	return false
+}
+
+// WebhookEvents — события, на которые подписан вебхук (JSONB)
+type WebhookEvents []string
+
+func (e WebhookEvents) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (e *WebhookEvents) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*e = WebhookEvents{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	}
+	return errors.New("unsupported type for WebhookEvents")
+}
+
+// Has сообщает, подписан ли вебхук на событие
+func (e WebhookEvents) Has(event string) bool {
+	for _, name := range e {
+		if name == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookPayload — тело доставки в том виде, в каком оно отправляется (JSONB)
+type WebhookPayload json.RawMessage
+
+func (p WebhookPayload) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "{}", nil
+	}
+	return string(p), nil
+}
+
+func (p *WebhookPayload) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		*p = append(WebhookPayload(nil), v...)
+		return nil
+	case string:
+		*p = WebhookPayload(v)
+		return nil
+	}
+	return errors.New("unsupported type for WebhookPayload")
+}
+
+func (p WebhookPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("null"), nil
+	}
+	return p, nil
+}
+
+type Webhook struct {
+	ID      uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	OwnerID uuid.UUID     `gorm:"type:uuid;not null" json:"owner_id"`
+	URL     string        `gorm:"not null" json:"url"`
+	Secret  string        `gorm:"not null" json:"-"`
+	Events  WebhookEvents `gorm:"type:jsonb;not null" json:"events"`
+	// AllTasks — события по всем задачам, а не только по видимым владельцу
+	AllTasks  bool      `gorm:"not null" json:"all_tasks"`
+	Active    bool      `gorm:"not null" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+func (Webhook) TableName() string {
+	return "task_schema.webhooks"
+}
+
+// WebhookDelivery — доставка одного события одному вебхуку
+type WebhookDelivery struct {
+	ID             uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	WebhookID      uuid.UUID      `gorm:"type:uuid;not null" json:"webhook_id"`
+	EventID        uuid.UUID      `gorm:"type:uuid;not null" json:"event_id"`
+	Event          string         `gorm:"not null" json:"event"`
+	TaskID         uuid.UUID      `gorm:"type:uuid;not null" json:"task_id"`
+	Payload        WebhookPayload `gorm:"type:jsonb;not null" json:"payload"`
+	Status         DeliveryStatus `gorm:"not null;default:pending" json:"status"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time      `gorm:"not null" json:"next_attempt_at"`
+	LastStatusCode *int           `json:"last_status_code"`
+	LastError      *string        `json:"last_error"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+func (WebhookDelivery) TableName() string {
+	return "task_schema.webhook_deliveries"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WorklogSource string
+
+const (
+	WorklogTimer  WorklogSource = "timer"
+	WorklogManual WorklogSource = "manual"
+)
+
+// TaskWorklog — затраченное на задачу время. Запись с пустым EndedAt — запущенный таймер.
+type TaskWorklog struct {
+	ID              uuid.UUID     `gorm:"type:uuid;default:gen_random_uuid();primary_key" json:"id"`
+	TaskID          uuid.UUID     `gorm:"type:uuid;not null" json:"task_id"`
+	UserID          uuid.UUID     `gorm:"type:uuid;not null" json:"user_id"`
+	Source          WorklogSource `gorm:"not null" json:"source"`
+	StartedAt       time.Time     `gorm:"not null" json:"started_at"`
+	EndedAt         *time.Time    `json:"ended_at"`
+	DurationSeconds *int64        `json:"duration_seconds"`
+	Note            string        `gorm:"not null;default:''" json:"note"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+func (w *TaskWorklog) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+func (TaskWorklog) TableName() string {
+	return "task_schema.task_worklogs"
+}
@@ -0,0 +1,140 @@
+// Package notify доставляет напоминания о сроках задач и уведомления наблюдателям
+// через подключаемые каналы
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"task-service/clients"
+	"task-service/config"
+
+	"github.com/google/uuid"
+)
+
+// Reminder — напоминание о сроке задачи. Kind — смещение до срока ("24h", "1h")
+// или "overdue"; Recipients — автор и исполнители задачи.
+type Reminder struct {
+	Kind       string      `json:"kind"`
+	TaskID     uuid.UUID   `json:"task_id"`
+	Title      string      `json:"title"`
+	Status     string      `json:"status"`
+	Priority   string      `json:"priority"`
+	DueDate    time.Time   `json:"due_date"`
+	Recipients []uuid.UUID `json:"recipients"`
+}
+
+// Subject — короткий текст напоминания для писем и логов
+func (r Reminder) Subject() string {
+	if r.Kind == "overdue" {
+		return fmt.Sprintf("Task %q is overdue", r.Title)
+	}
+	return fmt.Sprintf("Task %q is due in %s", r.Title, r.Kind)
+}
+
+// Activity — изменение задачи для её наблюдателей. Kind — status_changed, due_date_changed
+// или commented; Old и New — прежнее и новое значение поля, для комментария — его текст в Comment.
+// Recipients — наблюдатели задачи, кроме автора изменения.
+type Activity struct {
+	Kind       string      `json:"kind"`
+	TaskID     uuid.UUID   `json:"task_id"`
+	Title      string      `json:"title"`
+	ActorID    uuid.UUID   `json:"actor_id"`
+	Old        interface{} `json:"old,omitempty"`
+	New        interface{} `json:"new,omitempty"`
+	CommentID  *uuid.UUID  `json:"comment_id,omitempty"`
+	Comment    string      `json:"comment,omitempty"`
+	At         time.Time   `json:"at"`
+	Recipients []uuid.UUID `json:"recipients"`
+}
+
+// Subject — короткий текст уведомления для писем и логов
+func (a Activity) Subject() string {
+	switch a.Kind {
+	case "status_changed":
+		return fmt.Sprintf("Task %q status changed to %v", a.Title, a.New)
+	case "due_date_changed":
+		if a.New == nil {
+			return fmt.Sprintf("Task %q due date removed", a.Title)
+		}
+		return fmt.Sprintf("Task %q due date changed to %v", a.Title, a.New)
+	case "commented":
+		return fmt.Sprintf("New comment on task %q", a.Title)
+	}
+	return fmt.Sprintf("Task %q changed", a.Title)
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+	NotifyActivity(ctx context.Context, a Activity) error
+}
+
+// UserLookup находит email получателей (реализуется clients.AuthClient)
+type UserLookup interface {
+	LookupUsers(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]clients.UserInfo, error)
+}
+
+// Multi отправляет напоминание во все каналы и возвращает объединённую ошибку
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, r Reminder) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m Multi) NotifyActivity(ctx context.Context, a Activity) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.NotifyActivity(ctx, a); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogNotifier пишет напоминания в лог сервиса
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, r Reminder) error {
+	log.Printf("Reminder: %s (task %s, due %s, recipients %v)",
+		r.Subject(), r.TaskID, r.DueDate.Format(time.RFC3339), r.Recipients)
+	return nil
+}
+
+func (LogNotifier) NotifyActivity(ctx context.Context, a Activity) error {
+	log.Printf("Watcher notification: %s (task %s, actor %s, recipients %v)",
+		a.Subject(), a.TaskID, a.ActorID, a.Recipients)
+	return nil
+}
+
+// New собирает каналы из TASK_REMINDER_NOTIFIERS; они же доставляют уведомления наблюдателям
+func New(cfg *config.Config, users UserLookup) (Notifier, error) {
+	var notifiers Multi
+	for _, name := range cfg.ReminderNotifiers {
+		switch name {
+		case "log":
+			notifiers = append(notifiers, LogNotifier{})
+		case "webhook":
+			if cfg.ReminderWebhookURL == "" {
+				return nil, errors.New("TASK_REMINDER_WEBHOOK_URL is required for the webhook notifier")
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(cfg.ReminderWebhookURL))
+		case "smtp":
+			if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+				return nil, errors.New("SMTP_HOST and SMTP_FROM are required for the smtp notifier")
+			}
+			notifiers = append(notifiers, NewSMTPNotifier(cfg, users))
+		default:
+			return nil, fmt.Errorf("unknown notifier %q (allowed: log, webhook, smtp)", name)
+		}
+	}
+	return notifiers, nil
+}
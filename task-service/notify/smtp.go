@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-service/config"
+
+	"github.com/google/uuid"
+)
+
+// SMTPNotifier отправляет напоминания и уведомления наблюдателям письмом;
+// адреса получателей берутся из auth-service
+type SMTPNotifier struct {
+	Addr  string
+	Auth  smtp.Auth
+	From  string
+	Users UserLookup
+}
+
+func NewSMTPNotifier(cfg *config.Config, users UserLookup) *SMTPNotifier {
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &SMTPNotifier{
+		Addr:  net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		Auth:  auth,
+		From:  cfg.SMTPFrom,
+		Users: users,
+	}
+}
+
+func (s *SMTPNotifier) Notify(ctx context.Context, r Reminder) error {
+	body := fmt.Sprintf("%s\r\n\r\nDue: %s\r\nStatus: %s\r\nPriority: %s\r\nTask ID: %s\r\n",
+		r.Subject(), r.DueDate.UTC().Format(time.RFC1123), r.Status, r.Priority, r.TaskID)
+	if err := s.send(ctx, r.Recipients, r.Subject(), body); err != nil {
+		return fmt.Errorf("reminder email failed: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPNotifier) NotifyActivity(ctx context.Context, a Activity) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\r\n\r\n", a.Subject())
+	if a.Kind == "commented" {
+		fmt.Fprintf(&body, "%s\r\n\r\n", strings.ReplaceAll(a.Comment, "\n", "\r\n"))
+	} else {
+		fmt.Fprintf(&body, "Was: %s\r\nNow: %s\r\n", activityValue(a.Old), activityValue(a.New))
+	}
+	fmt.Fprintf(&body, "Task ID: %s\r\n", a.TaskID)
+
+	if err := s.send(ctx, a.Recipients, a.Subject(), body.String()); err != nil {
+		return fmt.Errorf("watcher email failed: %w", err)
+	}
+	return nil
+}
+
+var headerReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+func activityValue(v interface{}) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
+
+// send отправляет одно письмо всем получателям, у которых известен email. Адреса передаются
+// только в конверте SMTP (как Bcc): получатели не видят адреса друг друга.
+func (s *SMTPNotifier) send(ctx context.Context, recipients []uuid.UUID, subject, body string) error {
+	users, err := s.Users.LookupUsers(ctx, recipients)
+	if err != nil {
+		return err
+	}
+
+	var to []string
+	for _, u := range users {
+		if u.Email != "" {
+			to = append(to, u.Email)
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	msg.WriteString("To: undisclosed-recipients:;\r\n")
+	// Тема содержит название задачи: перевод строки в ней добавил бы в письмо чужие заголовки
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerReplacer.Replace(subject))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body)
+
+	return smtp.SendMail(s.Addr, s.Auth, s.From, to, []byte(msg.String()))
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier отправляет напоминания и уведомления наблюдателям POST-запросом с JSON-телом
+type WebhookNotifier struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, r Reminder) error {
+	return w.post(ctx, map[string]interface{}{
+		"event":    "task.reminder",
+		"reminder": r,
+	})
+}
+
+func (w *WebhookNotifier) NotifyActivity(ctx context.Context, a Activity) error {
+	return w.post(ctx, map[string]interface{}{
+		"event":    "task.watch",
+		"activity": a,
+	})
+}
+
+func (w *WebhookNotifier) post(ctx context.Context, payload map[string]interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s webhook failed: %w", payload["event"], err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s webhook returned status %d", payload["event"], resp.StatusCode)
+	}
+	return nil
+}
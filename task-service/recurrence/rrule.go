@@ -0,0 +1,291 @@
+// Package recurrence реализует подмножество iCalendar RRULE (RFC 5545):
+// FREQ=DAILY|WEEKLY|MONTHLY, INTERVAL, BYDAY (только для WEEKLY, без порядковых номеров),
+// BYMONTHDAY (только для MONTHLY, одно значение), UNTIL или COUNT.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+const maxInterval = 365
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	// ByMonthDay: 0 — день месяца первого повторения; отрицательные значения считаются с конца месяца
+	ByMonthDay int
+	Until      *time.Time
+	Count      int
+}
+
+// Parse разбирает правило вида "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=10" (префикс "RRULE:" допускается)
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("malformed rule part %q", part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly:
+				rule.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q (allowed: DAILY, WEEKLY, MONTHLY)", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxInterval {
+				return nil, fmt.Errorf("INTERVAL must be between 1 and %d", maxInterval)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			n, err := strconv.Atoi(value)
+			if err != nil || n == 0 || n < -31 || n > 31 {
+				return nil, errors.New("BYMONTHDAY must be between 1 and 31 or -31 and -1")
+			}
+			rule.ByMonthDay = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, errors.New("COUNT must be a positive integer")
+			}
+			rule.Count = n
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return nil, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if rule.ByMonthDay != 0 && rule.Freq != Monthly {
+		return nil, errors.New("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	if rule.Until != nil && rule.Count > 0 {
+		return nil, errors.New("UNTIL and COUNT cannot be combined")
+	}
+
+	sort.Slice(rule.ByDay, func(i, j int) bool {
+		return (rule.ByDay[i]+6)%7 < (rule.ByDay[j]+6)%7
+	})
+	return rule, nil
+}
+
+// UNTIL — дата (включительно, до конца дня UTC) или момент времени в UTC
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ, got %q", value)
+}
+
+// String возвращает правило в каноническом виде
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = weekdayNames[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.ByMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.ByMonthDay))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next возвращает дату повторения с номером occurrence+1: первую дату по правилу строго
+// после after. start — дата первого повторения (DTSTART), от неё отсчитываются интервалы
+// и берётся время суток. ok == false, если серия закончилась.
+func (r *Rule) Next(start, after time.Time, occurrence int) (time.Time, bool) {
+	if r.Count > 0 && occurrence >= r.Count {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	var ok bool
+	switch r.Freq {
+	case Daily:
+		next, ok = r.nextDaily(start, after)
+	case Weekly:
+		next, ok = r.nextWeekly(start, after)
+	case Monthly:
+		next, ok = r.nextMonthly(start, after)
+	}
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Upcoming возвращает до n следующих дат после after
+func (r *Rule) Upcoming(start, after time.Time, occurrence, n int) []time.Time {
+	dates := []time.Time{}
+	for i := 0; i < n; i++ {
+		next, ok := r.Next(start, after, occurrence+i)
+		if !ok {
+			break
+		}
+		dates = append(dates, next)
+		after = next
+	}
+	return dates
+}
+
+// Календарные вычисления ведутся в днях от эпохи в часовом поясе start,
+// чтобы переходы на летнее время не сдвигали даты.
+func dayNumber(t time.Time) int {
+	y, m, d := t.Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func atDay(start time.Time, day int) time.Time {
+	date := time.Unix(int64(day)*86400, 0).UTC()
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+}
+
+func (r *Rule) nextDaily(start, after time.Time) (time.Time, bool) {
+	s, a := dayNumber(start), dayNumber(after.In(start.Location()))
+	if a < s {
+		return atDay(start, s), true
+	}
+	return atDay(start, s+((a-s)/r.Interval+1)*r.Interval), true
+}
+
+func (r *Rule) nextWeekly(start, after time.Time) (time.Time, bool) {
+	days := r.ByDay
+	if len(days) == 0 {
+		days = []time.Weekday{start.Weekday()}
+	}
+	allowed := map[time.Weekday]bool{}
+	for _, d := range days {
+		allowed[d] = true
+	}
+
+	s, a := dayNumber(start), dayNumber(after.In(start.Location()))
+	// Недели начинаются с понедельника (WKST=MO)
+	weekStart := s - (int(start.Weekday())+6)%7
+
+	from := a + 1
+	if from < s {
+		from = s
+	}
+	for d := from; d <= from+7*r.Interval+7; d++ {
+		date := atDay(start, d)
+		if allowed[date.Weekday()] && ((d-weekStart)/7)%r.Interval == 0 {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) nextMonthly(start, after time.Time) (time.Time, bool) {
+	after = after.In(start.Location())
+	startMonth := start.Year()*12 + int(start.Month()) - 1
+
+	from := after
+	if from.Before(start) {
+		from = start.AddDate(0, 0, -1)
+	}
+	month := from.Year()*12 + int(from.Month()) - 1
+
+	for i := 0; i <= 48*r.Interval; i++ {
+		m := month + i
+		if (m-startMonth)%r.Interval != 0 {
+			continue
+		}
+		year, mon := m/12, time.Month(m%12+1)
+		last := time.Date(year, mon+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+		day := r.ByMonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		if day < 0 {
+			day = last + day + 1
+		}
+		// Месяцы без нужного числа пропускаются, как в RFC 5545
+		if day < 1 || day > last {
+			continue
+		}
+
+		candidate := time.Date(year, mon, day,
+			start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		if dayNumber(candidate) > dayNumber(from) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,37 @@
+// Package storage хранит содержимое вложений задач. Метаданные лежат в task_schema,
+// а сами файлы — в локальной папке или в S3-совместимом хранилище.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"task-service/config"
+)
+
+// ErrNotFound — объекта с таким ключом нет
+var ErrNotFound = errors.New("object not found")
+
+type Storage interface {
+	// Put сохраняет объект; size — точная длина r в байтах
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
+
+// New создаёт хранилище по TASK_ATTACHMENT_STORAGE
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.AttachmentStorage {
+	case "local":
+		return NewLocal(cfg.AttachmentDir)
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			return nil, errors.New("S3_ENDPOINT and S3_BUCKET are required for the s3 storage")
+		}
+		return NewS3(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKeyID, cfg.S3SecretAccessKey), nil
+	}
+	return nil, fmt.Errorf("unknown attachment storage %q (allowed: local, s3)", cfg.AttachmentStorage)
+}
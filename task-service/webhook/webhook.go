@@ -0,0 +1,145 @@
+// Package webhook подписывает и отправляет исходящие вебхуки о событиях задач
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// Заголовки доставки
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// Сколько байт ответа получателя сохраняется в журнале при ошибке
+	maxResponseExcerpt = 512
+)
+
+var errPrivateAddress = errors.New("webhook URL resolves to a private or loopback address")
+
+// Sign возвращает подпись тела: HMAC-SHA256 от "<timestamp>.<body>" с секретом вебхука.
+// Метка времени в подписи не даёт повторно отправить перехваченный запрос позже.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateURL проверяет адрес вебхука: абсолютный http(s) без логина и пароля
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("url must use http or https")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	if len(raw) > 2048 {
+		return errors.New("url is too long")
+	}
+	return nil
+}
+
+// privateIP — адреса, на которые вебхуки по умолчанию не отправляются:
+// сервисы внутренней сети не должны быть доступны через чужие вебхуки
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
+
+// Client отправляет доставки. Адрес проверяется при подключении, уже после разрешения имени,
+// поэтому DNS-имя, указывающее на внутренний адрес, тоже отклоняется.
+type Client struct {
+	HTTPClient *http.Client
+}
+
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Client{
+		HTTPClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// Перенаправления не выполняются: получатель должен ответить сам
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Delivery — одна отправка события
+type Delivery struct {
+	ID     string
+	Event  string
+	URL    string
+	Secret string
+	Body   []byte
+}
+
+// Send отправляет доставку и возвращает код ответа (0 — ответа не было).
+// Успехом считается только ответ 2xx.
+func (c *Client) Send(ctx context.Context, d Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "task-service-webhooks")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, d.Body))
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Ответ попадает в TEXT-колонку журнала: без NUL и некорректного UTF-8
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+		excerpt := strings.ToValidUTF8(strings.ReplaceAll(string(bytes.TrimSpace(raw)), "\x00", ""), "")
+		return resp.StatusCode, fmt.Errorf("status %d: %s", resp.StatusCode, excerpt)
+	}
+	return resp.StatusCode, nil
+}